@@ -1,78 +1,211 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+	"golang.org/x/time/rate"
 )
 
 const HostURL string = "https://api.hubapi.com"
 
+// idProperty values of the user endpoints.
+const (
+	IdPropertyUserId = "USER_ID"
+	IdPropertyEmail  = "EMAIL"
+)
+
+// UserIdProperty returns the idProperty identifying userId: USER_ID for
+// numeric HubSpot user IDs and EMAIL for anything else.
+func UserIdProperty(userId string) string {
+	if userId == "" {
+		return IdPropertyEmail
+	}
+	for _, r := range userId {
+		if r < '0' || r > '9' {
+			return IdPropertyEmail
+		}
+	}
+	return IdPropertyUserId
+}
+
+// userURL returns the URL of the user with the given numeric user ID or
+// email. Emails are lowercased as HubSpot stores them.
+func (c *Client) userURL(userId string) string {
+	idProperty := UserIdProperty(userId)
+	if idProperty == IdPropertyEmail {
+		userId = NormalizeEmail(userId)
+	}
+	return fmt.Sprintf("%s/settings/v3/users/%s?idProperty=%s", c.HostURL, url.PathEscape(userId), idProperty)
+}
+
+// NormalizeEmail returns email the way HubSpot stores it: lowercased and
+// without surrounding spaces.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 type User struct {
-	Id     string `json:"id"`
-	Email  string `json:"email"`
-	RoleId string `json:"roleId"`
+	Id               string   `json:"id"`
+	Email            string   `json:"email"`
+	RoleId           string   `json:"roleId"`
+	PrimaryTeamId    string   `json:"primaryTeamId,omitempty"`
+	SecondaryTeamIds []string `json:"secondaryTeamIds,omitempty"`
+	FirstName        string   `json:"firstName,omitempty"`
+	LastName         string   `json:"lastName,omitempty"`
+	SuperAdmin       bool     `json:"superAdmin"`
+	SendWelcomeEmail bool     `json:"-"`
 }
 
 type CreateUserRequestWithRole struct {
-	Email            string `json:"email"`
-	RoleId           string `json:"roleId"`
-	SendWelcomeEmail bool   `json:"sendWelcomeEmail"`
+	Email            string   `json:"email"`
+	RoleId           string   `json:"roleId"`
+	PrimaryTeamId    string   `json:"primaryTeamId,omitempty"`
+	SecondaryTeamIds []string `json:"secondaryTeamIds,omitempty"`
+	FirstName        string   `json:"firstName,omitempty"`
+	LastName         string   `json:"lastName,omitempty"`
+	SendWelcomeEmail bool     `json:"sendWelcomeEmail"`
 }
 
 type CreateUserRequestWithNoRole struct {
-	Email            string `json:"email"`
-	SendWelcomeEmail bool   `json:"sendWelcomeEmail"`
+	Email            string   `json:"email"`
+	PrimaryTeamId    string   `json:"primaryTeamId,omitempty"`
+	SecondaryTeamIds []string `json:"secondaryTeamIds,omitempty"`
+	FirstName        string   `json:"firstName,omitempty"`
+	LastName         string   `json:"lastName,omitempty"`
+	SendWelcomeEmail bool     `json:"sendWelcomeEmail"`
 }
 
 type UpdateUserRequest struct {
-	RoleId string `json:"roleId"`
+	RoleId           string   `json:"roleId"`
+	PrimaryTeamId    string   `json:"primaryTeamId,omitempty"`
+	SecondaryTeamIds []string `json:"secondaryTeamIds"`
+	FirstName        string   `json:"firstName,omitempty"`
+	LastName         string   `json:"lastName,omitempty"`
 }
 
-var (
-	Errors = make(map[int]string)
-)
-
-func init() {
-	Errors[400] = "Bad Request, StatusCode = 400"
-	Errors[404] = "User Does Not Exist , StatusCode = 404"
-	Errors[409] = "User Already Exist, StatusCode = 409"
-	Errors[401] = "Unauthorized Access, StatusCode = 401"
-	Errors[429] = "User Has Sent Too Many Request, StatusCode = 429"
+// TokenSource supplies the access token sent with every request. Refresh is
+// called with the token HubSpot rejected and returns a newer token, so that
+// requests failing with the same token concurrently share one refresh.
+type TokenSource interface {
+	Token() (string, error)
+	Refresh(failed string) (string, error)
 }
 
 type Client struct {
-	HostURL    string
-	HTTPClient *http.Client
-	Token      string
+	HostURL     string
+	HTTPClient  *http.Client
+	TokenSource TokenSource
+	// APIKey authenticates with a legacy hapikey query parameter instead of
+	// a bearer token when set.
+	APIKey string
+	// MaxRetries is how many times a failed request is retried.
+	MaxRetries int
+	// MaxBackoff caps the wait between two attempts of a request.
+	MaxBackoff time.Duration
+	// Limiter throttles every request sent by the client, including retries.
+	// It is shared by all operations using the client; nil disables it.
+	Limiter *rate.Limiter
+	// PageSize is the default page size of list requests.
+	PageSize int
 }
 
-func NewClient(token string) *Client {
+func NewClient(tokenSource TokenSource) *Client {
 	c := Client{
-		HTTPClient: &http.Client{},
-		HostURL:    HostURL,
-		Token:      token,
+		HTTPClient:  &http.Client{},
+		HostURL:     HostURL,
+		TokenSource: tokenSource,
+		MaxRetries:  DefaultMaxRetries,
+		MaxBackoff:  DefaultMaxBackoff,
+		Limiter:     rate.NewLimiter(DefaultRequestsPerSecond, DefaultBurst),
+		PageSize:    DefaultPageSize,
 	}
 	return &c
 }
 
-func (c *Client) GetUser(userId string) (*User, error) {
-	request, err := http.NewRequest("GET", fmt.Sprintf("%s/settings/v3/users/%s?idProperty=EMAIL", c.HostURL, userId), nil)
+// do sends the request, retrying rate limited requests and, for idempotent
+// methods, server errors and transient network errors with backoff.
+func (c *Client) do(request *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(request.Context()); err != nil {
+				return nil, err
+			}
+		}
+		attemptRequest, err := rewind(request)
+		if err != nil {
+			return nil, err
+		}
+		response, err := c.send(attemptRequest)
+		if attempt >= c.MaxRetries || !shouldRetry(request, response, err) {
+			return response, err
+		}
+		wait := c.backoff(attempt, response)
+		if response != nil {
+			log.Printf("[DEBUG] %s %s returned StatusCode = %d, retrying in %s", request.Method, request.URL.Path, response.StatusCode, wait)
+			response.Body.Close()
+		} else {
+			log.Printf("[DEBUG] %s %s failed: %v, retrying in %s", request.Method, request.URL.Path, err, wait)
+		}
+		if err := sleep(request.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends the request once with a bearer token. If HubSpot rejects the
+// token with a 401 the token is refreshed and the request is sent once more.
+func (c *Client) send(request *http.Request) (*http.Response, error) {
+	if c.APIKey != "" {
+		query := request.URL.Query()
+		query.Set("hapikey", c.APIKey)
+		request.URL.RawQuery = query.Encode()
+		return c.HTTPClient.Do(request)
+	}
+	accessToken, err := c.TokenSource.Token()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	response, err := c.HTTPClient.Do(request)
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+	response.Body.Close()
+	accessToken, err = c.TokenSource.Refresh(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := rewind(request)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", "Bearer "+accessToken)
+	return c.HTTPClient.Do(retry)
+}
+
+// GetUser returns the user with the given numeric user ID or email. Emails
+// are matched case-insensitively.
+func (c *Client) GetUser(ctx context.Context, userId string) (*User, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", c.userURL(userId), nil)
 	if err != nil {
 		log.Println("[READ ERROR]: ", err)
 		return nil, err
 	}
-	request.Header.Add("Authorization", "Bearer "+c.Token)
 	request.Header.Add("Accept", "application/json")
-	response, err := c.HTTPClient.Do(request)
+	response, err := c.do(request)
 	if err != nil {
 		log.Println("[READ ERROR]: ", err)
 		return nil, err
 	}
+	defer response.Body.Close()
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("READ ERROR : %v", Errors[response.StatusCode])
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
 	}
 	user := &User{}
 	err = json.NewDecoder(response.Body).Decode(user)
@@ -83,122 +216,134 @@ func (c *Client) GetUser(userId string) (*User, error) {
 	return user, nil
 }
 
-func (c *Client) CreateUser(user *User) error {
+// ListUsers returns every user of the account, following HubSpot's paging
+// cursor until the last page.
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	pager := c.NewPager("/settings/v3/users", nil, 0)
+	for pager.More() {
+		var page []User
+		if err := pager.Next(ctx, &page); err != nil {
+			return nil, err
+		}
+		users = append(users, page...)
+	}
+	return users, nil
+}
+
+// CreateUser creates the user and sets user.Id to the ID HubSpot assigned.
+func (c *Client) CreateUser(ctx context.Context, user *User) error {
+	var createUserRequest interface{}
 	if user.RoleId == "" {
-		createUserRequest := CreateUserRequestWithNoRole{
+		createUserRequest = CreateUserRequestWithNoRole{
 			Email:            user.Email,
-			SendWelcomeEmail: true,
-		}
-		reqjson, err := json.Marshal(createUserRequest)
-		if err != nil {
-			log.Println("[CREATE ERROR]: ", err)
-			return err
-		}
-		request, err := http.NewRequest("POST", fmt.Sprintf("%s/settings/v3/users/", c.HostURL), strings.NewReader(string(reqjson)))
-		if err != nil {
-			log.Println("[CREATE ERROR]: ", err)
-			return err
-		}
-		request.Header.Add("Content-Type", "application/json")
-		request.Header.Add("Authorization", "Bearer "+c.Token)
-		request.Header.Add("Accept", "application/json")
-		response, err := c.HTTPClient.Do(request)
-		if err != nil {
-			log.Println("[CREATE ERROR]: ", err)
-			return err
-		}
-		if response.StatusCode >= 200 && response.StatusCode <= 299 {
-			return nil
-		} else {
-			return fmt.Errorf("CREATE ERROR : %v", Errors[response.StatusCode])
+			PrimaryTeamId:    user.PrimaryTeamId,
+			SecondaryTeamIds: user.SecondaryTeamIds,
+			FirstName:        user.FirstName,
+			LastName:         user.LastName,
+			SendWelcomeEmail: user.SendWelcomeEmail,
 		}
 	} else {
-		createUserRequest := CreateUserRequestWithRole{
+		createUserRequest = CreateUserRequestWithRole{
 			Email:            user.Email,
 			RoleId:           user.RoleId,
-			SendWelcomeEmail: true,
-		}
-		reqjson, err := json.Marshal(createUserRequest)
-		if err != nil {
-			log.Println("[CREATE ERROR]: ", err)
-			return err
-		}
-		request, err := http.NewRequest("POST", fmt.Sprintf("%s/settings/v3/users/", c.HostURL), strings.NewReader(string(reqjson)))
-		if err != nil {
-			log.Println("[CREATE ERROR]: ", err)
-			return err
-		}
-		request.Header.Add("Content-Type", "application/json")
-		request.Header.Add("Authorization", "Bearer "+c.Token)
-		request.Header.Add("Accept", "application/json")
-		response, err := c.HTTPClient.Do(request)
-		if err != nil {
-			log.Println("[CREATE ERROR]: ", err)
-			return err
-		}
-		if response.StatusCode >= 200 && response.StatusCode <= 299 {
-			return nil
-		} else {
-			return fmt.Errorf("CREATE ERROR : %v", Errors[response.StatusCode])
+			PrimaryTeamId:    user.PrimaryTeamId,
+			SecondaryTeamIds: user.SecondaryTeamIds,
+			FirstName:        user.FirstName,
+			LastName:         user.LastName,
+			SendWelcomeEmail: user.SendWelcomeEmail,
 		}
 	}
+	reqjson, err := json.Marshal(createUserRequest)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/settings/v3/users/", c.HostURL), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("CREATE ERROR : %w", newAPIError(response))
+	}
+	created := &User{}
+	if err := json.NewDecoder(response.Body).Decode(created); err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	user.Id = created.Id
+	return nil
 }
 
-func (c *Client) UpdateUser(user *User) error {
+// UpdateUser updates the user identified by user.Id, or by user.Email when
+// the ID is unknown.
+func (c *Client) UpdateUser(ctx context.Context, user *User) error {
+	userId := user.Id
+	if userId == "" {
+		userId = user.Email
+	}
 	updateUserRequest := UpdateUserRequest{
-		RoleId: user.RoleId,
+		RoleId:           user.RoleId,
+		PrimaryTeamId:    user.PrimaryTeamId,
+		SecondaryTeamIds: user.SecondaryTeamIds,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+	}
+	if updateUserRequest.SecondaryTeamIds == nil {
+		updateUserRequest.SecondaryTeamIds = []string{}
 	}
 	updatejson, err := json.Marshal(updateUserRequest)
 	if err != nil {
 		log.Println("[UPDATE ERROR]: ", err)
 		return err
 	}
-	request, err := http.NewRequest("PUT", fmt.Sprintf("%s/settings/v3/users/%s?idProperty=EMAIL", c.HostURL, user.Email), strings.NewReader(string(updatejson)))
+	request, err := http.NewRequestWithContext(ctx, "PUT", c.userURL(userId), strings.NewReader(string(updatejson)))
 	if err != nil {
 		log.Println("[UPDATE ERROR]: ", err)
 		return err
 	}
 	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Authorization", "Bearer "+c.Token)
 	request.Header.Add("Accept", "application/json")
-	response, err := c.HTTPClient.Do(request)
+	response, err := c.do(request)
 	if err != nil {
 		log.Println("[UPDATE ERROR]: ", err)
 		return err
 	}
+	defer response.Body.Close()
 	if response.StatusCode >= 200 && response.StatusCode < 400 {
 		return nil
 	} else {
-		return fmt.Errorf("UPDATE Error : %v", Errors[response.StatusCode])
+		return fmt.Errorf("UPDATE Error : %w", newAPIError(response))
 	}
 }
 
-func (c *Client) DeleteUser(userId string) error {
-	request, err := http.NewRequest("DELETE", fmt.Sprintf("%s/settings/v3/users/%s?idProperty=EMAIL", c.HostURL, userId), nil)
+// DeleteUser deletes the user with the given numeric user ID or email.
+func (c *Client) DeleteUser(ctx context.Context, userId string) error {
+	request, err := http.NewRequestWithContext(ctx, "DELETE", c.userURL(userId), nil)
 	if err != nil {
 		log.Println("[DELETE ERROR]: ", err)
 		return err
 	}
-	request.Header.Add("Authorization", "Bearer "+c.Token)
 	request.Header.Add("Accept", "application/json")
-	response, err := c.HTTPClient.Do(request)
+	response, err := c.do(request)
 	if err != nil {
 		log.Println("[DELETE ERROR]: ", err)
 		return err
 	}
+	defer response.Body.Close()
 	if response.StatusCode >= 200 && response.StatusCode <= 299 {
 		return nil
 	} else {
 		log.Println("Broken Request")
-		return fmt.Errorf("DELETE ERROR : %v", Errors[response.StatusCode])
-	}
-}
-
-func (c *Client) IsRetry(err error) bool {
-	if err != nil {
-		if strings.Contains(err.Error(), "429") == true {
-			return true
-		}
+		return fmt.Errorf("DELETE ERROR : %w", newAPIError(response))
 	}
-	return false
 }
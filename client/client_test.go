@@ -1,19 +1,35 @@
 package client
 
 import (
-	"log"
-	"os"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"terraform-provider-hubspot/hubspottest"
 	"terraform-provider-hubspot/token"
 	"testing"
 	"github.com/stretchr/testify/assert"
 )
 
-func init() {
-	clientId := os.Getenv("HUBSPOT_CLIENT_ID")
-	clientSecret := os.Getenv("HUBSPOT_CLIENT_SECRET")
-	refreshToken := os.Getenv("HUBSPOT_REFRESH_TOKEN")
-	accessToken := token.GenerateToken(clientId, clientSecret, refreshToken)
-	os.Setenv("HUBSPOT_TOKEN", accessToken)
+func newTestClient(server *hubspottest.Server) *Client {
+	client := NewClient(token.StaticSource(hubspottest.AccessToken))
+	client.HostURL = server.URL
+	return client
+}
+
+func seedUsers(server *hubspottest.Server, users map[string]User) {
+	for _, user := range users {
+		server.AddUser(hubspottest.User{
+			Id:               user.Id,
+			Email:            user.Email,
+			RoleId:           user.RoleId,
+			PrimaryTeamId:    user.PrimaryTeamId,
+			SecondaryTeamIds: user.SecondaryTeamIds,
+			FirstName:        user.FirstName,
+			LastName:         user.LastName,
+			SuperAdmin:       user.SuperAdmin,
+		})
+	}
 }
 
 func TestClient_GetUser(t *testing.T) {
@@ -41,6 +57,40 @@ func TestClient_GetUser(t *testing.T) {
 				RoleId: "76894",
 			},
 		},
+		{
+			testName: "user exists by id",
+			userName: "24791265",
+			seedData: map[string]User{
+				"user1": {
+					Id:     "24791265",
+					Email:  "thesaurabhsaini@gmail.com",
+					RoleId: "76894",
+				},
+			},
+			expectErr: false,
+			expectedResp: &User{
+				Id:     "24791265",
+				Email:  "thesaurabhsaini@gmail.com",
+				RoleId: "76894",
+			},
+		},
+		{
+			testName: "email differs in case",
+			userName: "TheSaurabhSaini@Gmail.com",
+			seedData: map[string]User{
+				"user1": {
+					Id:     "24791265",
+					Email:  "thesaurabhsaini@gmail.com",
+					RoleId: "76894",
+				},
+			},
+			expectErr: false,
+			expectedResp: &User{
+				Id:     "24791265",
+				Email:  "thesaurabhsaini@gmail.com",
+				RoleId: "76894",
+			},
+		},
 		{
 			testName:     "user does not exist",
 			userName:     "saurabh.saini@clevertap.com",
@@ -48,13 +98,22 @@ func TestClient_GetUser(t *testing.T) {
 			expectErr:    true,
 			expectedResp: nil,
 		},
+		{
+			testName:     "user id does not exist",
+			userName:     "24791265",
+			seedData:     nil,
+			expectErr:    true,
+			expectedResp: nil,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			token := os.Getenv("HUBSPOT_TOKEN")
-			client := NewClient(token)
-			user, err := client.GetUser(tc.userName)
+			server := hubspottest.NewServer()
+			defer server.Close()
+			seedUsers(server, tc.seedData)
+			client := newTestClient(server)
+			user, err := client.GetUser(context.Background(), tc.userName)
 			if tc.expectErr {
 				assert.Error(t, err)
 				return
@@ -102,14 +161,20 @@ func TestClient_CreateUser(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			token := os.Getenv("HUBSPOT_TOKEN")
-			client := NewClient(token)
-			err := client.CreateUser(tc.newUser)
+			server := hubspottest.NewServer()
+			defer server.Close()
+			seedUsers(server, tc.seedData)
+			client := newTestClient(server)
+			newUser := &User{Email: tc.newUser.Email, RoleId: tc.newUser.RoleId}
+			err := client.CreateUser(context.Background(), newUser)
 			if tc.expectErr {
 				assert.Error(t, err)
 				return
 			}
 			assert.NoError(t, err)
+			created, ok := server.User(newUser.Email)
+			assert.True(t, ok)
+			assert.Equal(t, created.Id, newUser.Id)
 		})
 	}
 }
@@ -151,14 +216,16 @@ func TestClient_UpdateUser(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			token := os.Getenv("HUBSPOT_TOKEN")
-			client := NewClient(token)
-			err := client.UpdateUser(tc.updatedUser)
+			server := hubspottest.NewServer()
+			defer server.Close()
+			seedUsers(server, tc.seedData)
+			client := newTestClient(server)
+			err := client.UpdateUser(context.Background(), tc.updatedUser)
 			if tc.expectErr {
 				assert.Error(t, err)
 				return
 			}
-			user, err := client.GetUser(tc.updatedUser.Email)
+			user, err := client.GetUser(context.Background(), tc.updatedUser.Email)
 			assert.NoError(t, err)
 			assert.Equal(t, tc.updatedUser, user)
 		})
@@ -184,22 +251,212 @@ func TestClient_DeleteUser(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			testName: "user exists by id",
+			userName: "24813958",
+			seedData: map[string]User{
+				"user1": {
+					Id:     "24813958",
+					Email:  "ravikishandaiya@gmail.com",
+					RoleId: "76891",
+				},
+			},
+			expectErr: false,
+		},
+		{
+			testName:  "user does not exist",
+			userName:  "saurabh.saini@clevertap.com",
+			seedData:  nil,
+			expectErr: true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			token := os.Getenv("HUBSPOT_TOKEN")
-			client := NewClient(token)
-			_, err := client.GetUser(tc.userName)
-			if err != nil {
-				assert.NoError(t, err)
+			server := hubspottest.NewServer()
+			defer server.Close()
+			seedUsers(server, tc.seedData)
+			client := newTestClient(server)
+			err := client.DeleteUser(context.Background(), tc.userName)
+			if tc.expectErr {
+				assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
 				return
 			}
-			err = client.DeleteUser(tc.userName)
+			assert.NoError(t, err)
+			_, err = client.GetUser(context.Background(), tc.userName)
+			assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+		})
+	}
+}
+
+func TestClient_GetUserNormalizesEmail(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	client.GetUser(context.Background(), " Jane.Doe@Company.COM ")
+	assert.Contains(t, server.Requests(), "GET /settings/v3/users/jane.doe@company.com")
+}
+
+func TestUserIdProperty(t *testing.T) {
+	assert.Equal(t, IdPropertyUserId, UserIdProperty("24791265"))
+	assert.Equal(t, IdPropertyEmail, UserIdProperty("thesaurabhsaini@gmail.com"))
+	assert.Equal(t, IdPropertyEmail, UserIdProperty("2479x265"))
+	assert.Equal(t, IdPropertyEmail, UserIdProperty(""))
+}
+
+func seedRoles(server *hubspottest.Server, roles map[string]Role) {
+	for _, role := range roles {
+		server.AddRole(hubspottest.Role{
+			Id:                   role.Id,
+			Name:                 role.Name,
+			RequiresBillingWrite: role.RequiresBillingWrite,
+		})
+	}
+}
+
+func TestClient_GetRoles(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		roleName     string
+		seedData     map[string]Role
+		expectErr    bool
+		expectedResp *Role
+	}{
+		{
+			testName: "role exists",
+			roleName: "Sales",
+			seedData: map[string]Role{
+				"role1": {
+					Id:   "76891",
+					Name: "Sales",
+				},
+			},
+			expectErr: false,
+			expectedResp: &Role{
+				Id:   "76891",
+				Name: "Sales",
+			},
+		},
+		{
+			testName: "role name differs in case",
+			roleName: "sales",
+			seedData: map[string]Role{
+				"role1": {
+					Id:   "76891",
+					Name: "Sales",
+				},
+			},
+			expectErr: false,
+			expectedResp: &Role{
+				Id:   "76891",
+				Name: "Sales",
+			},
+		},
+		{
+			testName:     "role does not exist",
+			roleName:     "No Such Role",
+			seedData:     nil,
+			expectErr:    true,
+			expectedResp: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			server := hubspottest.NewServer()
+			defer server.Close()
+			seedRoles(server, tc.seedData)
+			client := newTestClient(server)
+			role, err := client.GetRoleByName(context.Background(), tc.roleName)
 			if tc.expectErr {
-				log.Println("[DELETE ERROR]: ", err)
 				assert.Error(t, err)
 				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResp, role)
 		})
 	}
 }
+
+type countingTokenSource struct {
+	tokens    []string
+	refreshes int
+}
+
+func (s *countingTokenSource) Token() (string, error) {
+	return s.tokens[s.refreshes], nil
+}
+
+func (s *countingTokenSource) Refresh(failed string) (string, error) {
+	s.refreshes++
+	return s.tokens[s.refreshes], nil
+}
+
+func TestClient_RefreshOnUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":"24791265","email":"thesaurabhsaini@gmail.com","roleId":"76894"}`))
+	}))
+	defer server.Close()
+
+	tokenSource := &countingTokenSource{tokens: []string{"expired", "fresh"}}
+	client := NewClient(tokenSource)
+	client.HostURL = server.URL
+	user, err := client.GetUser(context.Background(), "thesaurabhsaini@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "24791265", user.Id)
+	assert.Equal(t, 1, tokenSource.refreshes)
+}
+
+func TestClient_APIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("hapikey") != "key" || r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":"24791265","email":"thesaurabhsaini@gmail.com","roleId":"76894"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.HostURL = server.URL
+	client.APIKey = "key"
+	user, err := client.GetUser(context.Background(), "thesaurabhsaini@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "24791265", user.Id)
+}
+
+func TestClient_GetTeams(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	server.AddTeam(hubspottest.Team{
+		Id:               "4801",
+		Name:             "Sales EMEA",
+		UserIds:          []string{"24791265"},
+		SecondaryUserIds: []string{},
+	})
+	client := newTestClient(server)
+	teams, err := client.GetTeams(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Team{{
+		Id:               "4801",
+		Name:             "Sales EMEA",
+		UserIds:          []string{"24791265"},
+		SecondaryUserIds: []string{},
+	}}, teams)
+}
+
+func TestClient_ListUsers(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	for i := 0; i < 250; i++ {
+		server.AddUser(hubspottest.User{Email: fmt.Sprintf("user%d@clevertap.com", i)})
+	}
+	client := newTestClient(server)
+	users, err := client.ListUsers(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, users, 250)
+	assert.Len(t, server.Requests(), 3, "users are listed 100 per page")
+}
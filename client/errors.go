@@ -0,0 +1,87 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// APIError is an unsuccessful response from the HubSpot API, decoded from
+// HubSpot's standard JSON error body when one is returned.
+type APIError struct {
+	StatusCode    int           `json:"-"`
+	Status        string        `json:"status"`
+	Category      string        `json:"category"`
+	Message       string        `json:"message"`
+	CorrelationId string        `json:"correlationId"`
+	Errors        []ErrorDetail `json:"errors"`
+}
+
+// ErrorDetail is one entry of the errors array of an APIError.
+type ErrorDetail struct {
+	Message     string              `json:"message"`
+	In          string              `json:"in"`
+	Code        string              `json:"code"`
+	SubCategory string              `json:"subCategory"`
+	Context     map[string][]string `json:"context"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, StatusCode = %d", http.StatusText(e.StatusCode), e.StatusCode)
+	if e.Category != "" {
+		fmt.Fprintf(&b, ", Category = %s", e.Category)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	for _, detail := range e.Errors {
+		fmt.Fprintf(&b, "; %s", detail.Message)
+	}
+	if e.CorrelationId != "" {
+		fmt.Fprintf(&b, " (correlationId %s)", e.CorrelationId)
+	}
+	return b.String()
+}
+
+// newAPIError builds an APIError from a non-successful response. The body is
+// consumed but not closed.
+func newAPIError(response *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: response.StatusCode}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+	if err := json.Unmarshal(body, apiErr); err != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+// StatusCode returns the HTTP status code of an APIError wrapped in err, or 0.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+func IsRateLimited(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
+
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		statusCode    int
+		body          string
+		expectedErr   *APIError
+		expectedText  string
+		isNotFound    bool
+		isConflict    bool
+		isRateLimited bool
+	}{
+		{
+			testName:   "hubspot error body",
+			statusCode: 404,
+			body:       `{"status":"error","message":"User not found","correlationId":"8b2f1f7c","category":"OBJECT_NOT_FOUND"}`,
+			expectedErr: &APIError{
+				StatusCode:    404,
+				Status:        "error",
+				Category:      "OBJECT_NOT_FOUND",
+				Message:       "User not found",
+				CorrelationId: "8b2f1f7c",
+			},
+			expectedText: "Not Found, StatusCode = 404, Category = OBJECT_NOT_FOUND: User not found (correlationId 8b2f1f7c)",
+			isNotFound:   true,
+		},
+		{
+			testName:   "validation errors",
+			statusCode: 400,
+			body:       `{"status":"error","message":"Invalid input","category":"VALIDATION_ERROR","errors":[{"message":"roleId is invalid","in":"roleId"}]}`,
+			expectedErr: &APIError{
+				StatusCode: 400,
+				Status:     "error",
+				Category:   "VALIDATION_ERROR",
+				Message:    "Invalid input",
+				Errors: []ErrorDetail{
+					{Message: "roleId is invalid", In: "roleId"},
+				},
+			},
+			expectedText: "Bad Request, StatusCode = 400, Category = VALIDATION_ERROR: Invalid input; roleId is invalid",
+		},
+		{
+			testName:      "empty body",
+			statusCode:    429,
+			body:          "",
+			expectedErr:   &APIError{StatusCode: 429},
+			expectedText:  "Too Many Requests, StatusCode = 429",
+			isRateLimited: true,
+		},
+		{
+			testName:     "non json body",
+			statusCode:   409,
+			body:         "Conflict\n",
+			expectedErr:  &APIError{StatusCode: 409, Message: "Conflict"},
+			expectedText: "Conflict, StatusCode = 409: Conflict",
+			isConflict:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			response := &http.Response{
+				StatusCode: tc.statusCode,
+				Body:       ioutil.NopCloser(strings.NewReader(tc.body)),
+			}
+			apiErr := newAPIError(response)
+			assert.Equal(t, tc.expectedErr, apiErr)
+			assert.Equal(t, tc.expectedText, apiErr.Error())
+
+			err := fmt.Errorf("READ ERROR : %w", apiErr)
+			assert.Equal(t, tc.isNotFound, IsNotFound(err))
+			assert.Equal(t, tc.isConflict, IsConflict(err))
+			assert.Equal(t, tc.isRateLimited, IsRateLimited(err))
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type ObjectSchemaLabels struct {
+	Singular string `json:"singular"`
+	Plural   string `json:"plural"`
+}
+
+// ObjectSchemaAssociation is an association definition between a custom
+// object and another object type, such as 0-1 for contacts.
+type ObjectSchemaAssociation struct {
+	Id               string `json:"id,omitempty"`
+	FromObjectTypeId string `json:"fromObjectTypeId"`
+	ToObjectTypeId   string `json:"toObjectTypeId"`
+	Name             string `json:"name,omitempty"`
+}
+
+// ObjectSchema is the definition of a custom object. Properties are only
+// sent when the schema is created, later they are managed with the property
+// methods using ObjectTypeId as object type.
+type ObjectSchema struct {
+	Id                         string                    `json:"id,omitempty"`
+	ObjectTypeId               string                    `json:"objectTypeId,omitempty"`
+	FullyQualifiedName         string                    `json:"fullyQualifiedName,omitempty"`
+	Name                       string                    `json:"name"`
+	Labels                     ObjectSchemaLabels        `json:"labels"`
+	PrimaryDisplayProperty     string                    `json:"primaryDisplayProperty"`
+	RequiredProperties         []string                  `json:"requiredProperties"`
+	SearchableProperties       []string                  `json:"searchableProperties"`
+	SecondaryDisplayProperties []string                  `json:"secondaryDisplayProperties"`
+	Properties                 []Property                `json:"properties"`
+	Associations               []ObjectSchemaAssociation `json:"associations,omitempty"`
+}
+
+// UpdateObjectSchemaRequest is the body of a schema update, which cannot
+// change the name or the properties of a custom object.
+type UpdateObjectSchemaRequest struct {
+	Labels                     ObjectSchemaLabels `json:"labels"`
+	PrimaryDisplayProperty     string             `json:"primaryDisplayProperty"`
+	RequiredProperties         []string           `json:"requiredProperties"`
+	SearchableProperties       []string           `json:"searchableProperties"`
+	SecondaryDisplayProperties []string           `json:"secondaryDisplayProperties"`
+}
+
+func (c *Client) objectSchemasURL() string {
+	return fmt.Sprintf("%s/crm/v3/schemas", c.HostURL)
+}
+
+func (c *Client) objectSchemaURL(objectType string) string {
+	return fmt.Sprintf("%s/%s", c.objectSchemasURL(), url.PathEscape(objectType))
+}
+
+// GetObjectSchema returns the schema of a custom object by object type id,
+// such as 2-123456, or fully qualified name.
+func (c *Client) GetObjectSchema(ctx context.Context, objectType string) (*ObjectSchema, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", c.objectSchemaURL(objectType), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	objectSchema := &ObjectSchema{}
+	err = json.NewDecoder(response.Body).Decode(objectSchema)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	return objectSchema, nil
+}
+
+// CreateObjectSchema creates the custom object with its properties and
+// replaces objectSchema with the schema HubSpot created.
+func (c *Client) CreateObjectSchema(ctx context.Context, objectSchema *ObjectSchema) error {
+	createObjectSchemaRequest := *objectSchema
+	createObjectSchemaRequest.Associations = nil
+	reqjson, err := json.Marshal(createObjectSchemaRequest)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", c.objectSchemasURL(), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("CREATE ERROR : %w", newAPIError(response))
+	}
+	created := &ObjectSchema{}
+	if err := json.NewDecoder(response.Body).Decode(created); err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	*objectSchema = *created
+	return nil
+}
+
+func (c *Client) UpdateObjectSchema(ctx context.Context, objectSchema *ObjectSchema) error {
+	updateObjectSchemaRequest := UpdateObjectSchemaRequest{
+		Labels:                     objectSchema.Labels,
+		PrimaryDisplayProperty:     objectSchema.PrimaryDisplayProperty,
+		RequiredProperties:         objectSchema.RequiredProperties,
+		SearchableProperties:       objectSchema.SearchableProperties,
+		SecondaryDisplayProperties: objectSchema.SecondaryDisplayProperties,
+	}
+	reqjson, err := json.Marshal(updateObjectSchemaRequest)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "PATCH", c.objectSchemaURL(objectSchema.ObjectTypeId), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("UPDATE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+func (c *Client) DeleteObjectSchema(ctx context.Context, objectType string) error {
+	request, err := http.NewRequestWithContext(ctx, "DELETE", c.objectSchemaURL(objectType), nil)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("DELETE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+// CreateObjectSchemaAssociation associates the custom object with
+// association.ToObjectTypeId and sets association.Id.
+func (c *Client) CreateObjectSchemaAssociation(ctx context.Context, objectType string, association *ObjectSchemaAssociation) error {
+	reqjson, err := json.Marshal(association)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", c.objectSchemaURL(objectType)+"/associations", strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("CREATE ERROR : %w", newAPIError(response))
+	}
+	created := &ObjectSchemaAssociation{}
+	if err := json.NewDecoder(response.Body).Decode(created); err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	association.Id = created.Id
+	return nil
+}
+
+func (c *Client) DeleteObjectSchemaAssociation(ctx context.Context, objectType, associationId string) error {
+	request, err := http.NewRequestWithContext(ctx, "DELETE", c.objectSchemaURL(objectType)+"/associations/"+url.PathEscape(associationId), nil)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("DELETE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
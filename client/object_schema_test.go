@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"terraform-provider-hubspot/hubspottest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ObjectSchema(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+
+	objectSchema := &ObjectSchema{
+		Name:                   "car",
+		Labels:                 ObjectSchemaLabels{Singular: "Car", Plural: "Cars"},
+		PrimaryDisplayProperty: "model",
+		RequiredProperties:     []string{"model"},
+		SearchableProperties:   []string{"model", "vin"},
+		Properties: []Property{
+			{Name: "model", Label: "Model", Type: "string", FieldType: "text"},
+			{Name: "vin", Label: "VIN", Type: "string", FieldType: "text"},
+		},
+	}
+	assert.NoError(t, client.CreateObjectSchema(ctx, objectSchema))
+	assert.NotEmpty(t, objectSchema.ObjectTypeId)
+	assert.NotEmpty(t, objectSchema.FullyQualifiedName)
+	assert.Len(t, objectSchema.Properties, 2)
+
+	got, err := client.GetObjectSchema(ctx, objectSchema.FullyQualifiedName)
+	assert.NoError(t, err)
+	assert.Equal(t, objectSchema, got)
+
+	err = client.CreateObjectSchema(ctx, &ObjectSchema{Name: "car", Labels: objectSchema.Labels, Properties: objectSchema.Properties})
+	assert.True(t, IsConflict(err), "expected a 409, got %v", err)
+
+	// Properties added later are managed with the property methods.
+	assert.NoError(t, client.CreateProperty(ctx, objectSchema.ObjectTypeId, &Property{Name: "year", Label: "Year", Type: "number", FieldType: "number", GroupName: "car_information"}))
+	objectSchema.Labels.Plural = "Vehicles"
+	objectSchema.SecondaryDisplayProperties = []string{"year"}
+	assert.NoError(t, client.UpdateObjectSchema(ctx, objectSchema))
+	got, err = client.GetObjectSchema(ctx, objectSchema.ObjectTypeId)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vehicles", got.Labels.Plural)
+	assert.Equal(t, []string{"year"}, got.SecondaryDisplayProperties)
+	assert.Len(t, got.Properties, 3)
+
+	objectSchema.PrimaryDisplayProperty = "color"
+	err = client.UpdateObjectSchema(ctx, objectSchema)
+	assert.Equal(t, 400, StatusCode(err), "a display property must be defined, got %v", err)
+
+	association := &ObjectSchemaAssociation{FromObjectTypeId: objectSchema.ObjectTypeId, ToObjectTypeId: "0-1"}
+	assert.NoError(t, client.CreateObjectSchemaAssociation(ctx, objectSchema.ObjectTypeId, association))
+	assert.NotEmpty(t, association.Id)
+	got, err = client.GetObjectSchema(ctx, objectSchema.ObjectTypeId)
+	assert.NoError(t, err)
+	assert.Equal(t, []ObjectSchemaAssociation{*association}, got.Associations)
+	assert.NoError(t, client.DeleteObjectSchemaAssociation(ctx, objectSchema.ObjectTypeId, association.Id))
+	got, err = client.GetObjectSchema(ctx, objectSchema.ObjectTypeId)
+	assert.NoError(t, err)
+	assert.Empty(t, got.Associations)
+
+	assert.NoError(t, client.DeleteObjectSchema(ctx, objectSchema.ObjectTypeId))
+	_, err = client.GetObjectSchema(ctx, objectSchema.ObjectTypeId)
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+}
+
+func TestClient_CreateObjectSchemaValidation(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+
+	err := client.CreateObjectSchema(context.Background(), &ObjectSchema{
+		Name:                   "car",
+		Labels:                 ObjectSchemaLabels{Singular: "Car", Plural: "Cars"},
+		PrimaryDisplayProperty: "name",
+		Properties:             []Property{{Name: "model", Label: "Model", Type: "string", FieldType: "text"}},
+	})
+	assert.Equal(t, 400, StatusCode(err), "the primary display property must be defined, got %v", err)
+}
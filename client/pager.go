@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultPageSize is the page size of list requests, the maximum most
+// HubSpot list endpoints accept.
+const DefaultPageSize = 100
+
+// Pager walks a HubSpot list endpoint page by page, following the
+// paging.next.after cursor. Every page is requested through the client, so
+// pages are throttled and retried like any other request.
+type Pager struct {
+	client   *Client
+	path     string
+	query    url.Values
+	pageSize int
+	after    string
+	done     bool
+}
+
+type pageResponse struct {
+	Results json.RawMessage `json:"results"`
+	Paging  struct {
+		Next struct {
+			After string `json:"after"`
+		} `json:"next"`
+	} `json:"paging"`
+}
+
+// NewPager returns a pager over the list endpoint at path, relative to
+// HostURL. query holds additional query parameters and may be nil. A
+// pageSize of zero uses the client's PageSize.
+func (c *Client) NewPager(path string, query url.Values, pageSize int) *Pager {
+	if pageSize <= 0 {
+		pageSize = c.PageSize
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &Pager{
+		client:   c,
+		path:     path,
+		query:    query,
+		pageSize: pageSize,
+	}
+}
+
+// More reports whether Next has pages left to return.
+func (p *Pager) More() bool {
+	return !p.done
+}
+
+// Next requests the next page and decodes its results into results, which
+// must be a pointer to a slice.
+func (p *Pager) Next(ctx context.Context, results interface{}) error {
+	if p.done {
+		return fmt.Errorf("READ ERROR : no more pages of %s", p.path)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	query := url.Values{}
+	for k, v := range p.query {
+		query[k] = v
+	}
+	query.Set("limit", strconv.Itoa(p.pageSize))
+	if p.after != "" {
+		query.Set("after", p.after)
+	}
+	request, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s?%s", p.client.HostURL, p.path, query.Encode()), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := p.client.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	page := &pageResponse{}
+	if err := json.NewDecoder(response.Body).Decode(page); err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return err
+	}
+	if err := json.Unmarshal(page.Results, results); err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return err
+	}
+	p.after = page.Paging.Next.After
+	p.done = p.after == ""
+	return nil
+}
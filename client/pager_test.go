@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"terraform-provider-hubspot/hubspottest"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPager(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	for i := 0; i < 100; i++ {
+		server.AddUser(hubspottest.User{Email: fmt.Sprintf("user%d@clevertap.com", i)})
+	}
+	// The first page is rate limited once and retried.
+	server.FailNext(hubspottest.Failure{Method: "GET", Path: "/settings/v3/users", StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"0"}}})
+	client := newTestClient(server)
+	client.MaxBackoff = 10 * time.Millisecond
+
+	pager := client.NewPager("/settings/v3/users", nil, 40)
+	var sizes []int
+	var users []User
+	for pager.More() {
+		var page []User
+		assert.NoError(t, pager.Next(context.Background(), &page))
+		sizes = append(sizes, len(page))
+		users = append(users, page...)
+	}
+	assert.Equal(t, []int{40, 40, 20}, sizes)
+	assert.Len(t, users, 100)
+	assert.Len(t, server.Requests(), 4)
+	assert.Error(t, pager.Next(context.Background(), &users), "a finished pager has no more pages")
+}
+
+func TestPager_Cancel(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var users []User
+	err := client.NewPager("/settings/v3/users", nil, 0).Next(ctx, &users)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, server.Requests())
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PipelineStage is a stage of a pipeline. Deal stages carry a "probability"
+// and ticket stages a "ticketState" in their metadata.
+type PipelineStage struct {
+	Id           string            `json:"id,omitempty"`
+	Label        string            `json:"label"`
+	DisplayOrder int               `json:"displayOrder"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// Pipeline is a deal or ticket pipeline.
+type Pipeline struct {
+	Id           string          `json:"id,omitempty"`
+	Label        string          `json:"label"`
+	DisplayOrder int             `json:"displayOrder"`
+	Stages       []PipelineStage `json:"stages"`
+}
+
+// UpdatePipelineRequest is the body of a pipeline update. Stages are updated
+// one by one with the stage methods.
+type UpdatePipelineRequest struct {
+	Label        string `json:"label"`
+	DisplayOrder int    `json:"displayOrder"`
+}
+
+func (c *Client) pipelinesURL(objectType string) string {
+	return fmt.Sprintf("%s/crm/v3/pipelines/%s", c.HostURL, url.PathEscape(objectType))
+}
+
+func (c *Client) pipelineURL(objectType, pipelineId string) string {
+	return fmt.Sprintf("%s/%s", c.pipelinesURL(objectType), url.PathEscape(pipelineId))
+}
+
+func (c *Client) pipelineStagesURL(objectType, pipelineId string) string {
+	return fmt.Sprintf("%s/stages", c.pipelineURL(objectType, pipelineId))
+}
+
+func (c *Client) pipelineStageURL(objectType, pipelineId, stageId string) string {
+	return fmt.Sprintf("%s/%s", c.pipelineStagesURL(objectType, pipelineId), url.PathEscape(stageId))
+}
+
+func (c *Client) GetPipeline(ctx context.Context, objectType, pipelineId string) (*Pipeline, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", c.pipelineURL(objectType, pipelineId), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	pipeline := &Pipeline{}
+	err = json.NewDecoder(response.Body).Decode(pipeline)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// CreatePipeline creates the pipeline with its stages and sets the IDs
+// HubSpot assigned to them.
+func (c *Client) CreatePipeline(ctx context.Context, objectType string, pipeline *Pipeline) error {
+	reqjson, err := json.Marshal(pipeline)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", c.pipelinesURL(objectType), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("CREATE ERROR : %w", newAPIError(response))
+	}
+	created := &Pipeline{}
+	if err := json.NewDecoder(response.Body).Decode(created); err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	*pipeline = *created
+	return nil
+}
+
+func (c *Client) UpdatePipeline(ctx context.Context, objectType string, pipeline *Pipeline) error {
+	updatePipelineRequest := UpdatePipelineRequest{
+		Label:        pipeline.Label,
+		DisplayOrder: pipeline.DisplayOrder,
+	}
+	reqjson, err := json.Marshal(updatePipelineRequest)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "PATCH", c.pipelineURL(objectType, pipeline.Id), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("UPDATE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+// DeletePipeline archives the pipeline. HubSpot refuses to delete pipelines
+// with records in any of their stages.
+func (c *Client) DeletePipeline(ctx context.Context, objectType, pipelineId string) error {
+	request, err := http.NewRequestWithContext(ctx, "DELETE", c.pipelineURL(objectType, pipelineId), nil)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("DELETE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+func (c *Client) GetPipelineStage(ctx context.Context, objectType, pipelineId, stageId string) (*PipelineStage, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", c.pipelineStageURL(objectType, pipelineId, stageId), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	stage := &PipelineStage{}
+	err = json.NewDecoder(response.Body).Decode(stage)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	return stage, nil
+}
+
+// CreatePipelineStage adds the stage to the pipeline and sets stage.Id.
+func (c *Client) CreatePipelineStage(ctx context.Context, objectType, pipelineId string, stage *PipelineStage) error {
+	reqjson, err := json.Marshal(stage)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", c.pipelineStagesURL(objectType, pipelineId), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("CREATE ERROR : %w", newAPIError(response))
+	}
+	created := &PipelineStage{}
+	if err := json.NewDecoder(response.Body).Decode(created); err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	stage.Id = created.Id
+	return nil
+}
+
+func (c *Client) UpdatePipelineStage(ctx context.Context, objectType, pipelineId string, stage *PipelineStage) error {
+	updatePipelineStageRequest := *stage
+	updatePipelineStageRequest.Id = ""
+	reqjson, err := json.Marshal(updatePipelineStageRequest)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "PATCH", c.pipelineStageURL(objectType, pipelineId, stage.Id), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("UPDATE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+// DeletePipelineStage archives the stage. HubSpot refuses to delete stages
+// that still have records.
+func (c *Client) DeletePipelineStage(ctx context.Context, objectType, pipelineId, stageId string) error {
+	request, err := http.NewRequestWithContext(ctx, "DELETE", c.pipelineStageURL(objectType, pipelineId, stageId), nil)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("DELETE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
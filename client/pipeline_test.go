@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"terraform-provider-hubspot/hubspottest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Pipeline(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+
+	pipeline := &Pipeline{
+		Label: "Renewals",
+		Stages: []PipelineStage{
+			{Label: "Negotiation", DisplayOrder: 0, Metadata: map[string]string{"probability": "0.5"}},
+			{Label: "Won", DisplayOrder: 1, Metadata: map[string]string{"probability": "1"}},
+		},
+	}
+	assert.NoError(t, client.CreatePipeline(ctx, "deals", pipeline))
+	assert.NotEmpty(t, pipeline.Id)
+	assert.Len(t, pipeline.Stages, 2)
+	assert.NotEmpty(t, pipeline.Stages[0].Id)
+
+	got, err := client.GetPipeline(ctx, "deals", pipeline.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, pipeline, got)
+
+	pipeline.Label = "Renewal deals"
+	assert.NoError(t, client.UpdatePipeline(ctx, "deals", pipeline))
+	got, err = client.GetPipeline(ctx, "deals", pipeline.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Renewal deals", got.Label)
+	assert.Len(t, got.Stages, 2, "updating a pipeline must keep its stages")
+
+	_, err = client.GetPipeline(ctx, "tickets", pipeline.Id)
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+
+	assert.NoError(t, client.DeletePipeline(ctx, "deals", pipeline.Id))
+	_, err = client.GetPipeline(ctx, "deals", pipeline.Id)
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+}
+
+func TestClient_PipelineStage(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	pipeline := server.AddPipeline("tickets", hubspottest.Pipeline{
+		Label: "Support",
+		Stages: []hubspottest.PipelineStage{
+			{Label: "New", Metadata: map[string]string{"ticketState": "OPEN"}},
+		},
+	})
+	client := newTestClient(server)
+	ctx := context.Background()
+
+	stage := &PipelineStage{Label: "Closed", DisplayOrder: 1, Metadata: map[string]string{"ticketState": "CLOSED"}}
+	assert.NoError(t, client.CreatePipelineStage(ctx, "tickets", pipeline.Id, stage))
+	assert.NotEmpty(t, stage.Id)
+
+	got, err := client.GetPipelineStage(ctx, "tickets", pipeline.Id, stage.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, stage, got)
+
+	stage.Label = "Resolved"
+	assert.NoError(t, client.UpdatePipelineStage(ctx, "tickets", pipeline.Id, stage))
+	got, err = client.GetPipelineStage(ctx, "tickets", pipeline.Id, stage.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, stage, got)
+
+	invalid := &PipelineStage{Label: "Pending", Metadata: map[string]string{"ticketState": "WAITING"}}
+	assert.Equal(t, 400, StatusCode(client.CreatePipelineStage(ctx, "tickets", pipeline.Id, invalid)))
+
+	server.SetStageRecords(stage.Id, 3)
+	err = client.DeletePipelineStage(ctx, "tickets", pipeline.Id, stage.Id)
+	assert.Equal(t, 400, StatusCode(err))
+	assert.Equal(t, 400, StatusCode(client.DeletePipeline(ctx, "tickets", pipeline.Id)))
+
+	server.SetStageRecords(stage.Id, 0)
+	assert.NoError(t, client.DeletePipelineStage(ctx, "tickets", pipeline.Id, stage.Id))
+	_, err = client.GetPipelineStage(ctx, "tickets", pipeline.Id, stage.Id)
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+}
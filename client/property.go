@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PropertyOption is an option of an enumeration property.
+type PropertyOption struct {
+	Label        string `json:"label"`
+	Value        string `json:"value"`
+	Description  string `json:"description,omitempty"`
+	DisplayOrder int    `json:"displayOrder"`
+	Hidden       bool   `json:"hidden"`
+}
+
+// Property is a CRM property of an object type such as contacts or deals.
+type Property struct {
+	Name               string           `json:"name"`
+	Label              string           `json:"label"`
+	Type               string           `json:"type"`
+	FieldType          string           `json:"fieldType"`
+	GroupName          string           `json:"groupName"`
+	Description        string           `json:"description,omitempty"`
+	Options            []PropertyOption `json:"options"`
+	CalculationFormula string           `json:"calculationFormula,omitempty"`
+	Hidden             bool             `json:"hidden"`
+	FormField          bool             `json:"formField"`
+}
+
+// UpdatePropertyRequest is the body of a property update, which cannot
+// change the name of a property. Options are left unchanged when nil and
+// removed when empty.
+type UpdatePropertyRequest struct {
+	Label              string            `json:"label"`
+	Type               string            `json:"type"`
+	FieldType          string            `json:"fieldType"`
+	GroupName          string            `json:"groupName"`
+	Description        string            `json:"description"`
+	Options            *[]PropertyOption `json:"options,omitempty"`
+	CalculationFormula string            `json:"calculationFormula,omitempty"`
+	Hidden             bool              `json:"hidden"`
+	FormField          bool              `json:"formField"`
+}
+
+func (c *Client) propertiesURL(objectType string) string {
+	return fmt.Sprintf("%s/crm/v3/properties/%s", c.HostURL, url.PathEscape(objectType))
+}
+
+func (c *Client) propertyURL(objectType, name string) string {
+	return fmt.Sprintf("%s/%s", c.propertiesURL(objectType), url.PathEscape(name))
+}
+
+func (c *Client) GetProperty(ctx context.Context, objectType, name string) (*Property, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", c.propertyURL(objectType, name), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	property := &Property{}
+	err = json.NewDecoder(response.Body).Decode(property)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	return property, nil
+}
+
+func (c *Client) CreateProperty(ctx context.Context, objectType string, property *Property) error {
+	createPropertyRequest := *property
+	if createPropertyRequest.Options == nil {
+		createPropertyRequest.Options = []PropertyOption{}
+	}
+	reqjson, err := json.Marshal(createPropertyRequest)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", c.propertiesURL(objectType), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("CREATE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+// UpdateProperty updates the property. The options of the property are
+// replaced by property.Options unless it is nil, in which case they are
+// kept; an empty slice removes every option. Records store option values,
+// so changing the label, order or visibility of an option does not affect
+// them.
+func (c *Client) UpdateProperty(ctx context.Context, objectType string, property *Property) error {
+	updatePropertyRequest := UpdatePropertyRequest{
+		Label:              property.Label,
+		Type:               property.Type,
+		FieldType:          property.FieldType,
+		GroupName:          property.GroupName,
+		Description:        property.Description,
+		CalculationFormula: property.CalculationFormula,
+		Hidden:             property.Hidden,
+		FormField:          property.FormField,
+	}
+	if property.Options != nil {
+		updatePropertyRequest.Options = &property.Options
+	}
+	updatejson, err := json.Marshal(updatePropertyRequest)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "PATCH", c.propertyURL(objectType, property.Name), strings.NewReader(string(updatejson)))
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("UPDATE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+// DeleteProperty archives the property.
+func (c *Client) DeleteProperty(ctx context.Context, objectType, name string) error {
+	request, err := http.NewRequestWithContext(ctx, "DELETE", c.propertyURL(objectType, name), nil)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("DELETE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PropertyGroup groups the properties of an object type in HubSpot.
+type PropertyGroup struct {
+	Name         string `json:"name"`
+	Label        string `json:"label"`
+	DisplayOrder int    `json:"displayOrder"`
+}
+
+// UpdatePropertyGroupRequest is the body of a property group update, which
+// cannot change the name of a group.
+type UpdatePropertyGroupRequest struct {
+	Label        string `json:"label"`
+	DisplayOrder int    `json:"displayOrder"`
+}
+
+func (c *Client) propertyGroupsURL(objectType string) string {
+	return fmt.Sprintf("%s/groups", c.propertiesURL(objectType))
+}
+
+func (c *Client) propertyGroupURL(objectType, name string) string {
+	return fmt.Sprintf("%s/%s", c.propertyGroupsURL(objectType), url.PathEscape(name))
+}
+
+func (c *Client) GetPropertyGroup(ctx context.Context, objectType, name string) (*PropertyGroup, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", c.propertyGroupURL(objectType, name), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	group := &PropertyGroup{}
+	err = json.NewDecoder(response.Body).Decode(group)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	return group, nil
+}
+
+func (c *Client) CreatePropertyGroup(ctx context.Context, objectType string, group *PropertyGroup) error {
+	reqjson, err := json.Marshal(group)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", c.propertyGroupsURL(objectType), strings.NewReader(string(reqjson)))
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[CREATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("CREATE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+func (c *Client) UpdatePropertyGroup(ctx context.Context, objectType string, group *PropertyGroup) error {
+	updatejson, err := json.Marshal(UpdatePropertyGroupRequest{
+		Label:        group.Label,
+		DisplayOrder: group.DisplayOrder,
+	})
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "PATCH", c.propertyGroupURL(objectType, group.Name), strings.NewReader(string(updatejson)))
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[UPDATE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("UPDATE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
+
+// DeletePropertyGroup archives the group.
+func (c *Client) DeletePropertyGroup(ctx context.Context, objectType, name string) error {
+	request, err := http.NewRequestWithContext(ctx, "DELETE", c.propertyGroupURL(objectType, name), nil)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[DELETE ERROR]: ", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("DELETE ERROR : %w", newAPIError(response))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"terraform-provider-hubspot/hubspottest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_PropertyGroup(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+
+	group := &PropertyGroup{
+		Name:         "tf_preferences",
+		Label:        "Preferences",
+		DisplayOrder: 3,
+	}
+	assert.NoError(t, client.CreatePropertyGroup(ctx, "contacts", group))
+	assert.True(t, IsConflict(client.CreatePropertyGroup(ctx, "contacts", group)))
+
+	got, err := client.GetPropertyGroup(ctx, "contacts", "tf_preferences")
+	assert.NoError(t, err)
+	assert.Equal(t, group, got)
+
+	group.Label = "Contact preferences"
+	group.DisplayOrder = -1
+	assert.NoError(t, client.UpdatePropertyGroup(ctx, "contacts", group))
+	got, err = client.GetPropertyGroup(ctx, "contacts", "tf_preferences")
+	assert.NoError(t, err)
+	assert.Equal(t, group, got)
+
+	_, err = client.GetPropertyGroup(ctx, "deals", "tf_preferences")
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+
+	assert.NoError(t, client.DeletePropertyGroup(ctx, "contacts", "tf_preferences"))
+	_, err = client.GetPropertyGroup(ctx, "contacts", "tf_preferences")
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+}
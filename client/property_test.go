@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"terraform-provider-hubspot/hubspottest"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Property(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	ctx := context.Background()
+
+	property := &Property{
+		Name:      "favorite_color",
+		Label:     "Favorite color",
+		Type:      "enumeration",
+		FieldType: "select",
+		GroupName: "contactinformation",
+		Options: []PropertyOption{
+			{Label: "Red", Value: "red"},
+			{Label: "Blue", Value: "blue"},
+		},
+		FormField: true,
+	}
+	assert.NoError(t, client.CreateProperty(ctx, "contacts", property))
+	assert.True(t, IsConflict(client.CreateProperty(ctx, "contacts", property)))
+
+	got, err := client.GetProperty(ctx, "contacts", "favorite_color")
+	assert.NoError(t, err)
+	assert.Equal(t, property, got)
+
+	property.Label = "Favourite colour"
+	property.Options = nil
+	assert.NoError(t, client.UpdateProperty(ctx, "contacts", property))
+	got, err = client.GetProperty(ctx, "contacts", "favorite_color")
+	assert.NoError(t, err)
+	assert.Equal(t, "Favourite colour", got.Label)
+	assert.Len(t, got.Options, 2, "nil options must be left unchanged")
+
+	property.Options = []PropertyOption{
+		{Label: "Blue", Value: "blue", DisplayOrder: 0},
+		{Label: "Green", Value: "green", DisplayOrder: 1, Hidden: true},
+	}
+	assert.NoError(t, client.UpdateProperty(ctx, "contacts", property))
+	got, err = client.GetProperty(ctx, "contacts", "favorite_color")
+	assert.NoError(t, err)
+	assert.Equal(t, property.Options, got.Options)
+
+	property.Options = []PropertyOption{}
+	assert.NoError(t, client.UpdateProperty(ctx, "contacts", property))
+	got, err = client.GetProperty(ctx, "contacts", "favorite_color")
+	assert.NoError(t, err)
+	assert.Empty(t, got.Options, "empty options must remove every option")
+
+	_, err = client.GetProperty(ctx, "deals", "favorite_color")
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+
+	assert.NoError(t, client.DeleteProperty(ctx, "contacts", "favorite_color"))
+	_, err = client.GetProperty(ctx, "contacts", "favorite_color")
+	assert.True(t, IsNotFound(err), "expected a 404, got %v", err)
+	assert.True(t, IsNotFound(client.DeleteProperty(ctx, "contacts", "favorite_color")))
+}
+
+func TestClient_CreatePropertyValidation(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	client := newTestClient(server)
+	err := client.CreateProperty(context.Background(), "contacts", &Property{Name: "favorite_color"})
+	assert.Equal(t, 400, StatusCode(err))
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// HubSpot allows 100 requests per 10 seconds for most apps.
+	DefaultRequestsPerSecond = 10
+	DefaultBurst             = 10
+
+	DefaultMaxRetries = 5
+	DefaultMaxBackoff = 30 * time.Second
+	// minBackoff is the wait before the first retry; it doubles on every
+	// further attempt.
+	minBackoff = 500 * time.Millisecond
+)
+
+// rewind returns a copy of the request with a fresh body, so that the request
+// can be sent again.
+func rewind(request *http.Request) (*http.Request, error) {
+	clone := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+func shouldRetry(request *http.Request, response *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(request) && isTransient(request.Context(), err)
+	}
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+		return true
+	case response.StatusCode >= 500:
+		return isIdempotent(request)
+	}
+	return false
+}
+
+// isIdempotent reports whether the request can safely be repeated after a
+// failure that may have happened after HubSpot applied it.
+func isIdempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// backoff returns how long to wait before the next attempt. A Retry-After
+// header, or HubSpot's rate limit headers once the window is exhausted, take
+// precedence over exponential backoff with jitter. The wait never exceeds
+// MaxBackoff.
+func (c *Client) backoff(attempt int, response *http.Response) time.Duration {
+	wait, ok := time.Duration(0), false
+	if response != nil {
+		wait, ok = rateLimitWait(response.Header)
+	}
+	if !ok {
+		wait = minBackoff << uint(attempt)
+		if wait <= 0 || wait > c.MaxBackoff {
+			wait = c.MaxBackoff
+		}
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	}
+	if wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
+}
+
+// rateLimitWait returns the wait the response headers ask for, and whether
+// they ask for one at all. A Retry-After of zero or a past date asks to retry
+// right away.
+func rateLimitWait(header http.Header) (time.Duration, bool) {
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			if wait := time.Until(date); wait > 0 {
+				return wait, true
+			}
+			return 0, true
+		}
+	}
+	if header.Get("X-HubSpot-RateLimit-Remaining") == "0" {
+		if interval, err := strconv.Atoi(header.Get("X-HubSpot-RateLimit-Interval-Milliseconds")); err == nil && interval > 0 {
+			return time.Duration(interval) * time.Millisecond, true
+		}
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
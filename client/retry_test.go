@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"terraform-provider-hubspot/token"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestClient_Retry(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		responses     []int
+		header        http.Header
+		call          func(c *Client) error
+		expectErr     bool
+		expectedCalls int
+	}{
+		{
+			testName:  "rate limited read is retried",
+			responses: []int{429, 200},
+			header:    http.Header{"Retry-After": {"1"}},
+			call: func(c *Client) error {
+				_, err := c.GetUser(context.Background(), "thesaurabhsaini@gmail.com")
+				return err
+			},
+			expectErr:     false,
+			expectedCalls: 2,
+		},
+		{
+			testName:  "rate limited create is retried",
+			responses: []int{429, 201},
+			header:    http.Header{"X-Hubspot-Ratelimit-Remaining": {"0"}, "X-Hubspot-Ratelimit-Interval-Milliseconds": {"10000"}},
+			call: func(c *Client) error {
+				return c.CreateUser(context.Background(), &User{Email: "thesaurabhsaini@gmail.com"})
+			},
+			expectErr:     false,
+			expectedCalls: 2,
+		},
+		{
+			testName:  "server error on read is retried",
+			responses: []int{502, 503, 200},
+			call: func(c *Client) error {
+				_, err := c.GetUser(context.Background(), "thesaurabhsaini@gmail.com")
+				return err
+			},
+			expectErr:     false,
+			expectedCalls: 3,
+		},
+		{
+			testName:  "server error on create is not retried",
+			responses: []int{500, 201},
+			call: func(c *Client) error {
+				return c.CreateUser(context.Background(), &User{Email: "thesaurabhsaini@gmail.com"})
+			},
+			expectErr:     true,
+			expectedCalls: 1,
+		},
+		{
+			testName:  "retries are exhausted",
+			responses: []int{429, 429, 429, 429},
+			call: func(c *Client) error {
+				return c.DeleteUser(context.Background(), "thesaurabhsaini@gmail.com")
+			},
+			expectErr:     true,
+			expectedCalls: 3,
+		},
+		{
+			testName:  "client errors are not retried",
+			responses: []int{404, 200},
+			call: func(c *Client) error {
+				_, err := c.GetUser(context.Background(), "thesaurabhsaini@gmail.com")
+				return err
+			},
+			expectErr:     true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tc.responses[calls]
+				calls++
+				for k, v := range tc.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(status)
+				if status == http.StatusOK || status == http.StatusCreated {
+					w.Write([]byte(`{"id":"24791265","email":"thesaurabhsaini@gmail.com"}`))
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(token.StaticSource("token"))
+			client.HostURL = server.URL
+			client.MaxRetries = 2
+			client.MaxBackoff = 10 * time.Millisecond
+			err := tc.call(client)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
+
+func TestClient_Backoff(t *testing.T) {
+	client := NewClient(token.StaticSource("token"))
+	client.MaxBackoff = 30 * time.Second
+
+	retryAfter := &http.Response{Header: http.Header{"Retry-After": {"7"}}}
+	assert.Equal(t, 7*time.Second, client.backoff(0, retryAfter))
+
+	exhausted := &http.Response{Header: http.Header{
+		"X-Hubspot-Ratelimit-Remaining":             {"0"},
+		"X-Hubspot-Ratelimit-Interval-Milliseconds": {"10000"},
+	}}
+	assert.Equal(t, 10*time.Second, client.backoff(0, exhausted))
+
+	retryNow := &http.Response{Header: http.Header{"Retry-After": {"0"}}}
+	assert.Equal(t, time.Duration(0), client.backoff(3, retryNow), "Retry-After: 0 retries right away")
+
+	pastDate := &http.Response{Header: http.Header{"Retry-After": {time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)}}}
+	assert.Equal(t, time.Duration(0), client.backoff(3, pastDate))
+
+	tooLong := &http.Response{Header: http.Header{"Retry-After": {"120"}}}
+	assert.Equal(t, 30*time.Second, client.backoff(0, tooLong))
+
+	for attempt := 0; attempt < 10; attempt++ {
+		wait := client.backoff(attempt, nil)
+		expected := minBackoff << uint(attempt)
+		if expected > client.MaxBackoff {
+			expected = client.MaxBackoff
+		}
+		assert.True(t, wait >= expected/2 && wait <= expected, "attempt %d waited %s", attempt, wait)
+	}
+}
+
+func TestClient_Limiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(token.StaticSource("token"))
+	client.HostURL = server.URL
+	client.Limiter = rate.NewLimiter(20, 1)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := client.GetRoles(context.Background())
+		assert.NoError(t, err)
+	}
+	// The first request uses the burst, the other four wait 50ms each.
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "requests were not throttled")
+}
+
+func TestClient_Cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(token.StaticSource("token"))
+	client.HostURL = server.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetUser(ctx, "thesaurabhsaini@gmail.com")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(start) < 5*time.Second, "request was not cancelled")
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Role struct {
+	Id                   string `json:"id"`
+	Name                 string `json:"name"`
+	RequiresBillingWrite bool   `json:"requiresBillingWrite"`
+}
+
+type RolesResponse struct {
+	Results []Role `json:"results"`
+}
+
+func (c *Client) GetRoles(ctx context.Context) ([]Role, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/settings/v3/users/roles", c.HostURL), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	roles := &RolesResponse{}
+	err = json.NewDecoder(response.Body).Decode(roles)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	return roles.Results, nil
+}
+
+// GetRoleByName returns the role with the given name. Names are compared
+// ignoring case, an exact match wins over roles differing only in case.
+func (c *Client) GetRoleByName(ctx context.Context, name string) (*Role, error) {
+	roles, err := c.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var match *Role
+	names := make([]string, 0, len(roles))
+	for i, role := range roles {
+		if role.Name == name {
+			return &roles[i], nil
+		}
+		if match == nil && strings.EqualFold(role.Name, name) {
+			match = &roles[i]
+		}
+		names = append(names, strconv.Quote(role.Name))
+	}
+	if match != nil {
+		return match, nil
+	}
+	return nil, fmt.Errorf("READ ERROR : Role %q Does Not Exist, available roles: %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type Team struct {
+	Id               string   `json:"id"`
+	Name             string   `json:"name"`
+	UserIds          []string `json:"userIds"`
+	SecondaryUserIds []string `json:"secondaryUserIds"`
+}
+
+type TeamsResponse struct {
+	Results []Team `json:"results"`
+}
+
+func (c *Client) GetTeams(ctx context.Context) ([]Team, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/settings/v3/users/teams", c.HostURL), nil)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	response, err := c.do(request)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("READ ERROR : %w", newAPIError(response))
+	}
+	teams := &TeamsResponse{}
+	err = json.NewDecoder(response.Body).Decode(teams)
+	if err != nil {
+		log.Println("[READ ERROR]: ", err)
+		return nil, err
+	}
+	return teams.Results, nil
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportOptions customizes the HTTP client built by NewHTTPClient.
+type TransportOptions struct {
+	// ProxyURL is the proxy every request goes through. When empty the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	ProxyURL string
+	// CABundle holds PEM encoded certificates trusted in addition to the
+	// system roots.
+	CABundle []byte
+	// Timeout bounds a single request, including reading the response body.
+	// Zero means no timeout.
+	Timeout time.Duration
+}
+
+func NewHTTPClient(options TransportOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", options.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if len(options.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(options.CABundle) {
+			return nil, fmt.Errorf("CA bundle does not contain any PEM encoded certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   options.Timeout,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"terraform-provider-hubspot/token"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPClient_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[{"id":"76891","name":"Sales"}]}`))
+	}))
+	defer server.Close()
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	client := NewClient(token.StaticSource("token"))
+	client.HostURL = server.URL
+	_, err := client.GetRoles(context.Background())
+	assert.Error(t, err, "the test server certificate must not be trusted by default")
+
+	client.HTTPClient, err = NewHTTPClient(TransportOptions{CABundle: bundle})
+	assert.NoError(t, err)
+	roles, err := client.GetRoles(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Role{{Id: "76891", Name: "Sales"}}, roles)
+
+	_, err = NewHTTPClient(TransportOptions{CABundle: []byte("not a certificate")})
+	assert.Error(t, err)
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	var proxied *url.URL
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL
+		w.Write([]byte(`{"results":[]}`))
+	}))
+	defer proxy.Close()
+
+	httpClient, err := NewHTTPClient(TransportOptions{ProxyURL: proxy.URL})
+	assert.NoError(t, err)
+	client := NewClient(token.StaticSource("token"))
+	client.HTTPClient = httpClient
+	client.HostURL = "http://api.hubapi.example"
+	_, err = client.GetRoles(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, proxied) {
+		assert.Equal(t, "api.hubapi.example", proxied.Host)
+		assert.Equal(t, "/settings/v3/users/roles", proxied.Path)
+	}
+}
@@ -0,0 +1,37 @@
+package hubspot
+
+import (
+	"context"
+	"terraform-provider-hubspot/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRole() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRoleRead,
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"requires_billing_write": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRoleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	role, err := apiClient.GetRoleByName(ctx, d.Get("name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(role.Id)
+	d.Set("name", role.Name)
+	d.Set("requires_billing_write", role.RequiresBillingWrite)
+	return diags
+}
@@ -0,0 +1,53 @@
+package hubspot
+
+import (
+	"fmt"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccRoleDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hubspot_role.sales", "id", "76891"),
+					resource.TestCheckResourceAttr("data.hubspot_role.sales", "name", "Sales"),
+				),
+			},
+		},
+	})
+}
+
+func testAccRoleDataSourceConfig() string {
+	return fmt.Sprintf(`
+	data "hubspot_role" "sales" {
+		name = "Sales"
+	}
+	`)
+}
+
+func TestAccRolesDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRolesDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.hubspot_roles.all", "roles.0.id"),
+					resource.TestCheckResourceAttrSet("data.hubspot_roles.all", "roles.0.name"),
+				),
+			},
+		},
+	})
+}
+
+func testAccRolesDataSourceConfig() string {
+	return fmt.Sprintf(`
+	data "hubspot_roles" "all" {}
+	`)
+}
@@ -0,0 +1,60 @@
+package hubspot
+
+import (
+	"context"
+	"strconv"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRoles() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRolesRead,
+		Schema: map[string]*schema.Schema{
+			"roles": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"requires_billing_write": &schema.Schema{
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRolesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	roles, err := apiClient.GetRoles(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	items := make([]interface{}, 0, len(roles))
+	for _, role := range roles {
+		items = append(items, map[string]interface{}{
+			"id":                     role.Id,
+			"name":                   role.Name,
+			"requires_billing_write": role.RequiresBillingWrite,
+		})
+	}
+	if err := d.Set("roles", items); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	return diags
+}
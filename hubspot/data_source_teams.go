@@ -0,0 +1,71 @@
+package hubspot
+
+import (
+	"context"
+	"strconv"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceTeams() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceTeamsRead,
+		Schema: map[string]*schema.Schema{
+			"teams": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_ids": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"secondary_user_ids": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceTeamsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	teams, err := apiClient.GetTeams(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	items := make([]interface{}, 0, len(teams))
+	for _, team := range teams {
+		items = append(items, map[string]interface{}{
+			"id":                 team.Id,
+			"name":               team.Name,
+			"user_ids":           team.UserIds,
+			"secondary_user_ids": team.SecondaryUserIds,
+		})
+	}
+	if err := d.Set("teams", items); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	return diags
+}
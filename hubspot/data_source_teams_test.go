@@ -0,0 +1,29 @@
+package hubspot
+
+import (
+	"fmt"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTeamsDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTeamsDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.hubspot_teams.all", "teams.0.id"),
+					resource.TestCheckResourceAttrSet("data.hubspot_teams.all", "teams.0.name"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTeamsDataSourceConfig() string {
+	return fmt.Sprintf(`
+	data "hubspot_teams" "all" {}
+	`)
+}
@@ -1,45 +1,98 @@
 package hubspot
 
 import (
-	"fmt"
-	"strings"
+	"context"
 	"terraform-provider-hubspot/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataSourceUser() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceUserRead,
+		ReadContext: dataSourceUserRead,
 		Schema: map[string]*schema.Schema{
 			"id": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Deprecated:   "Use email or user_id instead.",
+				ExactlyOneOf: []string{"id", "email", "user_id"},
 			},
 			"email": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "email", "user_id"},
+			},
+			"user_id": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "email", "user_id"},
+			},
+			"role_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
 			},
-			"role_id": &schema.Schema{
+			"primary_team_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"secondary_team_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"first_name": &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"last_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"super_admin": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
 		},
 	}
 }
 
-func dataSourceUserRead(d *schema.ResourceData, m interface{}) error {
+func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
 	apiClient := m.(*client.Client)
+	// id predates email and user_id and accepts either; it keeps the value
+	// it was given so that existing configurations see no diff.
 	userId := d.Get("id").(string)
-	user, err := apiClient.GetUser(userId)
+	legacyId := userId != ""
+	if !legacyId {
+		userId = d.Get("user_id").(string)
+	}
+	if userId == "" {
+		userId = d.Get("email").(string)
+	}
+	user, err := apiClient.GetUser(ctx, userId)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			d.SetId("")
-		} else {
-			return fmt.Errorf("error finding Item with ID %s", userId)
+		if client.IsNotFound(err) {
+			return diag.Errorf("user with ID %s does not exist", userId)
 		}
+		return diag.Errorf("error finding user with ID %s: %s", userId, err)
+	}
+	if legacyId {
+		d.SetId(userId)
+	} else {
+		d.SetId(user.Id)
 	}
-	d.SetId(user.Email)
+	d.Set("user_id", user.Id)
 	d.Set("email", user.Email)
 	d.Set("role_id", user.RoleId)
-	return nil
+	d.Set("primary_team_id", user.PrimaryTeamId)
+	d.Set("secondary_team_ids", user.SecondaryTeamIds)
+	d.Set("first_name", user.FirstName)
+	d.Set("last_name", user.LastName)
+	d.Set("super_admin", user.SuperAdmin)
+	return diags
 }
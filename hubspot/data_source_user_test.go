@@ -8,14 +8,22 @@ import (
 
 func TestAccUserDataSource_basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccUserDataSourceConfig(),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr(
 						"data.hubspot_user.user1", "id", "thesaurabhsaini@gmail.com"),
+					resource.TestCheckResourceAttr(
+						"data.hubspot_user.user1", "user_id", "24791265"),
+					resource.TestCheckResourceAttr(
+						"data.hubspot_user.user2", "id", "24791265"),
+					resource.TestCheckResourceAttr(
+						"data.hubspot_user.user2", "role_id", "76894"),
+					resource.TestCheckResourceAttr(
+						"data.hubspot_user.user3", "email", "thesaurabhsaini@gmail.com"),
 				),
 			},
 		},
@@ -31,5 +39,11 @@ func testAccUserDataSourceConfig() string {
 	data "hubspot_user" "user1" {
 		id = "thesaurabhsaini@gmail.com"
 	}
+	data "hubspot_user" "user2" {
+		email = "thesaurabhsaini@gmail.com"
+	}
+	data "hubspot_user" "user3" {
+		user_id = "24791265"
+	}
 	`)
 }
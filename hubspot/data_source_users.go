@@ -0,0 +1,136 @@
+package hubspot
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceUsers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceUsersRead,
+		Schema: map[string]*schema.Schema{
+			"role_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"team_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"email_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"super_admin": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"users": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"email": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"role_id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"primary_team_id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"secondary_team_ids": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"first_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"super_admin": &schema.Schema{
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	users, err := apiClient.ListUsers(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	emailRegex := regexp.MustCompile(d.Get("email_regex").(string))
+	roleId := d.Get("role_id").(string)
+	teamId := d.Get("team_id").(string)
+	superAdmin, filterSuperAdmin := d.GetOkExists("super_admin")
+
+	items := make([]interface{}, 0, len(users))
+	for _, user := range users {
+		if roleId != "" && user.RoleId != roleId {
+			continue
+		}
+		if teamId != "" && !userInTeam(user, teamId) {
+			continue
+		}
+		if !emailRegex.MatchString(user.Email) {
+			continue
+		}
+		if filterSuperAdmin && user.SuperAdmin != superAdmin.(bool) {
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"id":                 user.Id,
+			"email":              user.Email,
+			"role_id":            user.RoleId,
+			"primary_team_id":    user.PrimaryTeamId,
+			"secondary_team_ids": user.SecondaryTeamIds,
+			"first_name":         user.FirstName,
+			"last_name":          user.LastName,
+			"super_admin":        user.SuperAdmin,
+		})
+	}
+	if err := d.Set("users", items); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	return diags
+}
+
+func userInTeam(user client.User, teamId string) bool {
+	if user.PrimaryTeamId == teamId {
+		return true
+	}
+	for _, id := range user.SecondaryTeamIds {
+		if id == teamId {
+			return true
+		}
+	}
+	return false
+}
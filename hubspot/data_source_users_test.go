@@ -0,0 +1,37 @@
+package hubspot
+
+import (
+	"fmt"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccUsersDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUsersDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hubspot_users.service", "users.#", "1"),
+					resource.TestCheckResourceAttr("data.hubspot_users.service", "users.0.id", "24791265"),
+					resource.TestCheckResourceAttr("data.hubspot_users.service", "users.0.email", "thesaurabhsaini@gmail.com"),
+					resource.TestCheckResourceAttr("data.hubspot_users.admins", "users.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccUsersDataSourceConfig() string {
+	return fmt.Sprintf(`
+	data "hubspot_users" "service" {
+		role_id     = "76894"
+		email_regex = "@gmail\\.com$"
+	}
+	data "hubspot_users" "admins" {
+		super_admin = true
+	}
+	`)
+}
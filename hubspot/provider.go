@@ -1,9 +1,19 @@
 package hubspot
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
 	"terraform-provider-hubspot/client"
 	"terraform-provider-hubspot/token"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/time/rate"
 )
 
 func Provider() *schema.Provider {
@@ -27,18 +37,203 @@ func Provider() *schema.Provider {
 				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("HUBSPOT_REFRESH_TOKEN", nil),
 			},
+			"access_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("HUBSPOT_ACCESS_TOKEN", nil),
+			},
+			"api_key": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("HUBSPOT_API_KEY", nil),
+			},
+			"max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      client.DefaultMaxRetries,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"max_backoff": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      client.DefaultMaxBackoff.String(),
+				ValidateFunc: validateDuration,
+			},
+			"requests_per_second": &schema.Schema{
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      client.DefaultRequestsPerSecond,
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"burst": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      client.DefaultBurst,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"page_size": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("HUBSPOT_PAGE_SIZE", client.DefaultPageSize),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"base_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("HUBSPOT_BASE_URL", client.HostURL),
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"oauth_token_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("HUBSPOT_OAUTH_TOKEN_URL", token.TokenURL),
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"http_proxy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("HUBSPOT_HTTP_PROXY", nil),
+				ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5"}),
+			},
+			"request_timeout": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1m",
+				ValidateFunc: validateDuration,
+			},
+			"ca_bundle": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_bundle_file"},
+			},
+			"ca_bundle_file": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("HUBSPOT_CA_BUNDLE_FILE", nil),
+				ConflictsWith: []string{"ca_bundle"},
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"hubspot_user": resourceUser(),
+			"hubspot_user":                 resourceUser(),
+			"hubspot_property":             resourceProperty(),
+			"hubspot_property_group":       resourcePropertyGroup(),
+			"hubspot_pipeline":             resourcePipeline(),
+			"hubspot_pipeline_stage":       resourcePipelineStage(),
+			"hubspot_custom_object_schema": resourceCustomObjectSchema(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"hubspot_user": dataSourceUser(),
+			"hubspot_user":  dataSourceUser(),
+			"hubspot_users": dataSourceUsers(),
+			"hubspot_role":  dataSourceRole(),
+			"hubspot_roles": dataSourceRoles(),
+			"hubspot_teams": dataSourceTeams(),
 		},
-		ConfigureFunc: providerConfigure,
+		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	accessToken := token.GenerateToken(d.Get("client_id").(string), d.Get("client_secret").(string), d.Get("refresh_token").(string))
-	return client.NewClient(accessToken), nil
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	clientId := d.Get("client_id").(string)
+	clientSecret := d.Get("client_secret").(string)
+	refreshToken := d.Get("refresh_token").(string)
+	accessToken := d.Get("access_token").(string)
+	apiKey := d.Get("api_key").(string)
+
+	oauth := clientId != "" || clientSecret != "" || refreshToken != ""
+	modes := 0
+	for _, configured := range []bool{oauth, accessToken != "", apiKey != ""} {
+		if configured {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid authentication configuration",
+			Detail:   "Exactly one authentication mode must be configured: either client_id, client_secret and refresh_token (OAuth), access_token (private app) or api_key (legacy API key).",
+		})
+	}
+
+	httpClient, err := newHTTPClient(d)
+	if err != nil {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid HTTP transport configuration",
+			Detail:   err.Error(),
+		})
+	}
+
+	switch {
+	case accessToken != "":
+		return configureClient(client.NewClient(token.StaticSource(accessToken)), d, httpClient), diags
+	case apiKey != "":
+		c := client.NewClient(nil)
+		c.APIKey = apiKey
+		return configureClient(c, d, httpClient), diags
+	}
+	if clientId == "" || clientSecret == "" || refreshToken == "" {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Incomplete OAuth configuration",
+			Detail:   "OAuth authentication requires client_id, client_secret and refresh_token to all be set.",
+		})
+	}
+	tokenSource := token.NewRefreshSource(clientId, clientSecret, refreshToken)
+	tokenSource.TokenURL = d.Get("oauth_token_url").(string)
+	tokenSource.HTTPClient = httpClient
+	if _, err := tokenSource.Token(); err != nil {
+		summary := "Unable to obtain a HubSpot access token"
+		var oauthErr *token.OAuthError
+		if errors.As(err, &oauthErr) {
+			summary = "Unable to obtain a HubSpot access token: " + oauthErr.Summary()
+		}
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   err.Error(),
+		})
+	}
+	return configureClient(client.NewClient(tokenSource), d, httpClient), diags
+}
+
+// newHTTPClient builds the HTTP client shared by the API client and the
+// OAuth token source from the provider's transport settings.
+func newHTTPClient(d *schema.ResourceData) (*http.Client, error) {
+	options := client.TransportOptions{
+		ProxyURL: d.Get("http_proxy").(string),
+		CABundle: []byte(d.Get("ca_bundle").(string)),
+	}
+	options.Timeout, _ = time.ParseDuration(d.Get("request_timeout").(string))
+	if path := d.Get("ca_bundle_file").(string); path != "" {
+		bundle, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca_bundle_file: %w", err)
+		}
+		options.CABundle = bundle
+	}
+	return client.NewHTTPClient(options)
+}
+
+// configureClient applies the provider's request settings to the client.
+func configureClient(c *client.Client, d *schema.ResourceData, httpClient *http.Client) *client.Client {
+	c.HostURL = strings.TrimSuffix(d.Get("base_url").(string), "/")
+	c.HTTPClient = httpClient
+	c.MaxRetries = d.Get("max_retries").(int)
+	c.MaxBackoff, _ = time.ParseDuration(d.Get("max_backoff").(string))
+	c.PageSize = d.Get("page_size").(int)
+	c.Limiter = nil
+	if requestsPerSecond := d.Get("requests_per_second").(float64); requestsPerSecond > 0 {
+		c.Limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), d.Get("burst").(int))
+	}
+	return c
+}
+
+func validateDuration(v interface{}, k string) (ws []string, es []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		es = append(es, fmt.Errorf("%s is not a valid duration: %s", k, err))
+	}
+	return
 }
@@ -1,26 +1,162 @@
 package hubspot
 
 import (
+	"context"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
-	"terraform-provider-hubspot/token"
+	"path/filepath"
+	"terraform-provider-hubspot/client"
+	"terraform-provider-hubspot/hubspottest"
 	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
-var testAccProviders map[string]*schema.Provider
-var testAccProvider *schema.Provider
+var testAccServer *hubspottest.Server
 
 func init() {
-	clientId := os.Getenv("HUBSPOT_CLIENT_ID")
-	clientSecret := os.Getenv("HUBSPOT_CLIENT_SECRET")
-	refreshToken := os.Getenv("HUBSPOT_REFRESH_TOKEN")
-	accessToken := token.GenerateToken(clientId, clientSecret, refreshToken)
-	os.Setenv("HUBSPOT_TOKEN", string(accessToken))
-	testAccProvider = Provider()
-	testAccProviders = map[string]*schema.Provider{
-		"hubspot": testAccProvider,
+	testAccServer = newTestAccServer()
+}
+
+// testAccLive reports whether acceptance tests run against the real HubSpot
+// API, configured by the usual HUBSPOT_* variables.
+func testAccLive() bool {
+	return !testAccFake() && (os.Getenv("HUBSPOT_LIVE") != "" || testAccRecording())
+}
+
+// testAccFake reports whether acceptance tests run against the fake server
+// even when they have a cassette.
+func testAccFake() bool {
+	return os.Getenv("HUBSPOT_FAKE") == "1"
+}
+
+// testAccRecording reports whether acceptance tests record their interactions
+// with the real HubSpot API, or the fake server, to cassettes.
+func testAccRecording() bool {
+	return os.Getenv("HUBSPOT_RECORD") == "1"
+}
+
+// testAccRecorder returns the recorder of the test's cassette
+// testdata/cassettes/<test>.json, or nil when the test talks to HubSpot or
+// the fake server without recording. A test without a cassette runs against
+// the fake server.
+func testAccRecorder(t *testing.T) *hubspottest.Recorder {
+	path := filepath.Join("testdata", "cassettes", t.Name()+".json")
+	if testAccRecording() {
+		recorder, err := hubspottest.NewRecorder(path, hubspottest.ModeRecord, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := recorder.Stop(); err != nil {
+				t.Errorf("writing cassette: %s", err)
+			}
+		})
+		return recorder
+	}
+	if testAccLive() || testAccFake() {
+		return nil
+	}
+	recorder, err := hubspottest.NewRecorder(path, hubspottest.ModeReplay, nil)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recorder
+}
+
+// testAccProvider returns a provider talking to HubSpot, or to the fake server
+// unless the test is live or replays a cassette, that sends every API request
+// through the recorder when there is one.
+func testAccProvider(recorder *hubspottest.Recorder) *schema.Provider {
+	p := Provider()
+	if testAccFake() || (recorder == nil && !testAccLive()) {
+		p = testAccServer.Provider(Provider)
+	} else if recorder != nil && !testAccRecording() {
+		// Replayed cassettes need no credentials; a placeholder access token
+		// keeps the provider from requesting an OAuth token.
+		defaults := map[string]interface{}{
+			"client_id":      nil,
+			"client_secret":  nil,
+			"refresh_token":  nil,
+			"api_key":        nil,
+			"access_token":   "replayed",
+			"base_url":       client.HostURL,
+			"http_proxy":     nil,
+			"ca_bundle_file": nil,
+		}
+		for name, value := range defaults {
+			value := value
+			p.Schema[name].DefaultFunc = func() (interface{}, error) {
+				return value, nil
+			}
+		}
+	}
+	if recorder == nil {
+		return p
+	}
+	configure := p.ConfigureContextFunc
+	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		meta, diags := configure(ctx, d)
+		if c, ok := meta.(*client.Client); ok {
+			c.HTTPClient.Transport = recorder.Wrap(c.HTTPClient.Transport)
+		}
+		return meta, diags
+	}
+	return p
+}
+
+// testAccProviderFactories returns the providers of an acceptance test, which
+// replay the test's cassette, or use the fake server when it has none, unless
+// HUBSPOT_LIVE, HUBSPOT_FAKE or HUBSPOT_RECORD is set.
+func testAccProviderFactories(t *testing.T) map[string]func() (*schema.Provider, error) {
+	var recorder *hubspottest.Recorder
+	// resource.Test skips the test without TF_ACC, so there is nothing to
+	// replay.
+	if os.Getenv(resource.TestEnvVar) != "" {
+		recorder = testAccRecorder(t)
+	}
+	return map[string]func() (*schema.Provider, error){
+		"hubspot": func() (*schema.Provider, error) {
+			return testAccProvider(recorder), nil
+		},
+	}
+}
+
+// testAccClient returns the API client of a test calling resource functions
+// directly. Like the acceptance tests it replays the test's cassette, or uses
+// the fake server, so it runs without Terraform.
+func testAccClient(t *testing.T) *client.Client {
+	p := testAccProvider(testAccRecorder(t))
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(nil)); diags.HasError() {
+		t.Fatalf("err: %v", diags)
 	}
+	return p.Meta().(*client.Client)
+}
+
+// newTestAccServer returns a fake HubSpot seeded with the roles, teams and
+// users the acceptance tests refer to.
+func newTestAccServer() *hubspottest.Server {
+	server := hubspottest.NewServer()
+	server.AddRole(hubspottest.Role{Id: "76891", Name: "Sales"})
+	server.AddRole(hubspottest.Role{Id: "76894", Name: "Service"})
+	server.AddTeam(hubspottest.Team{Id: "4801", Name: "Sales EMEA", UserIds: []string{}, SecondaryUserIds: []string{}})
+	server.AddTeam(hubspottest.Team{Id: "4802", Name: "Sales APAC", UserIds: []string{}, SecondaryUserIds: []string{}})
+	server.AddUser(hubspottest.User{Id: "24791265", Email: "thesaurabhsaini@gmail.com", RoleId: "76894"})
+	server.AddPipeline("deals", hubspottest.Pipeline{
+		Id:    "default",
+		Label: "Sales Pipeline",
+		Stages: []hubspottest.PipelineStage{
+			{Id: "appointmentscheduled", Label: "Appointment Scheduled", Metadata: map[string]string{"probability": "0.2"}},
+		},
+	})
+	return server
 }
 
 func TestProvider(t *testing.T) {
@@ -35,7 +171,143 @@ func TestProvider_impl(t *testing.T) {
 }
 
 func testAccPreCheck(t *testing.T) {
-	if v := os.Getenv("HUBSPOT_TOKEN"); v == "" {
-		t.Fatal("HUBSPOT_TOKEN must be set for acceptance tests")
+	if !testAccLive() {
+		return
+	}
+	if os.Getenv("HUBSPOT_ACCESS_TOKEN") == "" && os.Getenv("HUBSPOT_REFRESH_TOKEN") == "" && os.Getenv("HUBSPOT_API_KEY") == "" {
+		t.Fatal("HUBSPOT_ACCESS_TOKEN, HUBSPOT_API_KEY or HUBSPOT_CLIENT_ID, HUBSPOT_CLIENT_SECRET and HUBSPOT_REFRESH_TOKEN must be set for live acceptance tests")
+	}
+}
+
+func TestProvider_authModes(t *testing.T) {
+	testCases := []struct {
+		testName  string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{
+			testName: "private app access token",
+			config: map[string]interface{}{
+				"access_token": "pat-na1-token",
+			},
+			expectErr: false,
+		},
+		{
+			testName: "legacy api key",
+			config: map[string]interface{}{
+				"api_key": "key",
+			},
+			expectErr: false,
+		},
+		{
+			testName:  "no credentials",
+			config:    map[string]interface{}{},
+			expectErr: true,
+		},
+		{
+			testName: "incomplete oauth",
+			config: map[string]interface{}{
+				"client_id": "id",
+			},
+			expectErr: true,
+		},
+		{
+			testName: "two modes",
+			config: map[string]interface{}{
+				"access_token": "pat-na1-token",
+				"api_key":      "key",
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, Provider().Schema, tc.config)
+			_, diags := providerConfigure(context.Background(), d)
+			if tc.expectErr && !diags.HasError() {
+				t.Fatal("expected an error")
+			}
+			if !tc.expectErr && diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+		})
+	}
+}
+
+func TestProvider_oauth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("refresh_token") != "refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"BAD_REFRESH_TOKEN","message":"missing or unknown refresh token"}`))
+			return
+		}
+		w.Write([]byte(`{"refresh_token":"refresh","access_token":"access","expires_in":1800}`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		testName        string
+		refreshToken    string
+		expectedSummary string
+	}{
+		{
+			testName:        "valid refresh token",
+			refreshToken:    "refresh",
+			expectedSummary: "",
+		},
+		{
+			testName:        "revoked refresh token",
+			refreshToken:    "revoked",
+			expectedSummary: "Unable to obtain a HubSpot access token: refresh token revoked or unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+				"client_id":       "id",
+				"client_secret":   "secret",
+				"refresh_token":   tc.refreshToken,
+				"oauth_token_url": server.URL,
+				"base_url":        "https://api.hubapi.example/",
+			})
+			meta, diags := providerConfigure(context.Background(), d)
+			if tc.expectedSummary != "" {
+				if !diags.HasError() || diags[0].Summary != tc.expectedSummary {
+					t.Fatalf("expected %q, got %v", tc.expectedSummary, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+			if hostURL := meta.(*client.Client).HostURL; hostURL != "https://api.hubapi.example" {
+				t.Fatalf("unexpected HostURL %s", hostURL)
+			}
+		})
+	}
+}
+
+func TestProvider_pageSize(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{"access_token": "pat-na1-token"})
+	meta, diags := providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if pageSize := meta.(*client.Client).PageSize; pageSize != client.DefaultPageSize {
+		t.Fatalf("unexpected default PageSize %d", pageSize)
+	}
+
+	os.Setenv("HUBSPOT_PAGE_SIZE", "25")
+	defer os.Unsetenv("HUBSPOT_PAGE_SIZE")
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{"access_token": "pat-na1-token"})
+	meta, diags = providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if pageSize := meta.(*client.Client).PageSize; pageSize != 25 {
+		t.Fatalf("unexpected PageSize %d from HUBSPOT_PAGE_SIZE", pageSize)
 	}
 }
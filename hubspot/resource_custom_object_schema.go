@@ -0,0 +1,470 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceCustomObjectSchema manages the definition of a custom object. Only
+// a change of name replaces the object: the labels and the display, required
+// and searchable properties are patched on the schema, the property
+// definitions with the properties API and the associated object types with
+// the associations API.
+func resourceCustomObjectSchema() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCustomObjectSchemaCreate,
+		ReadContext:   resourceCustomObjectSchemaRead,
+		UpdateContext: resourceCustomObjectSchemaUpdate,
+		DeleteContext: resourceCustomObjectSchemaDelete,
+		CustomizeDiff: resourceCustomObjectSchemaCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceCustomObjectSchemaImporter,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"singular_label": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"plural_label": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"primary_display_property": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"required_properties": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"searchable_properties": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"secondary_display_properties": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"property": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"label": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"type": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"bool", "enumeration", "date", "datetime", "string", "number"}, false),
+						},
+						"field_type": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"group_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+						"description": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"option": propertyOptionSchema(),
+					},
+				},
+			},
+			"associated_object_types": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"object_type_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"fully_qualified_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceCustomObjectSchemaCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	objectSchema := expandObjectSchema(d)
+	objectSchema.Properties = expandObjectSchemaProperties(d.Get("property").([]interface{}))
+	if err := apiClient.CreateObjectSchema(ctx, &objectSchema); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(objectSchema.ObjectTypeId)
+	for _, v := range d.Get("associated_object_types").(*schema.Set).List() {
+		association := client.ObjectSchemaAssociation{FromObjectTypeId: objectSchema.ObjectTypeId, ToObjectTypeId: v.(string)}
+		if err := apiClient.CreateObjectSchemaAssociation(ctx, objectSchema.ObjectTypeId, &association); err != nil {
+			return append(resourceCustomObjectSchemaRead(ctx, d, m), diag.FromErr(err)...)
+		}
+	}
+	return resourceCustomObjectSchemaRead(ctx, d, m)
+}
+
+func resourceCustomObjectSchemaRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectSchema, err := apiClient.GetObjectSchema(ctx, d.Id())
+	if err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
+	known := expandObjectSchemaProperties(d.Get("property").([]interface{}))
+	associated := make([]interface{}, 0, len(objectSchema.Associations))
+	for _, association := range objectSchema.Associations {
+		if association.FromObjectTypeId == objectSchema.ObjectTypeId {
+			associated = append(associated, association.ToObjectTypeId)
+		}
+	}
+	d.Set("name", objectSchema.Name)
+	d.Set("singular_label", objectSchema.Labels.Singular)
+	d.Set("plural_label", objectSchema.Labels.Plural)
+	d.Set("primary_display_property", objectSchema.PrimaryDisplayProperty)
+	d.Set("required_properties", objectSchema.RequiredProperties)
+	d.Set("searchable_properties", objectSchema.SearchableProperties)
+	d.Set("secondary_display_properties", objectSchema.SecondaryDisplayProperties)
+	d.Set("property", flattenObjectSchemaProperties(selectObjectSchemaProperties(objectSchema.Properties, known)))
+	d.Set("associated_object_types", associated)
+	d.Set("object_type_id", objectSchema.ObjectTypeId)
+	d.Set("fully_qualified_name", objectSchema.FullyQualifiedName)
+	return diags
+}
+
+// resourceCustomObjectSchemaUpdate creates the added properties before
+// patching the schema, which may refer to them, and deletes the removed
+// properties last, once the schema no longer refers to them.
+func resourceCustomObjectSchemaUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	objectTypeId := d.Id()
+	old, new := d.GetChange("property")
+	oldProperties := expandObjectSchemaProperties(old.([]interface{}))
+	properties := expandObjectSchemaProperties(new.([]interface{}))
+	added, changed, removed := diffObjectSchemaProperties(oldProperties, properties)
+	// On failure, read the added and removed properties from HubSpot so that
+	// the state has those that exist.
+	failed := func(err error) diag.Diagnostics {
+		d.Set("property", flattenObjectSchemaProperties(append(properties, removed...)))
+		return append(resourceCustomObjectSchemaRead(ctx, d, m), diag.FromErr(err)...)
+	}
+
+	for i := range added {
+		if added[i].GroupName == "" && len(oldProperties) > 0 {
+			// Properties created with the object got their group from
+			// HubSpot, put the new property in the same group.
+			added[i].GroupName = oldProperties[0].GroupName
+		}
+		if err := apiClient.CreateProperty(ctx, objectTypeId, &added[i]); err != nil {
+			return failed(err)
+		}
+	}
+	if d.HasChanges("singular_label", "plural_label", "primary_display_property", "required_properties", "searchable_properties", "secondary_display_properties") {
+		objectSchema := expandObjectSchema(d)
+		objectSchema.ObjectTypeId = objectTypeId
+		if err := apiClient.UpdateObjectSchema(ctx, &objectSchema); err != nil {
+			return failed(err)
+		}
+	}
+	for i := range changed {
+		if err := apiClient.UpdateProperty(ctx, objectTypeId, &changed[i]); err != nil {
+			return failed(err)
+		}
+	}
+	if d.HasChange("associated_object_types") {
+		old, new := d.GetChange("associated_object_types")
+		for _, v := range new.(*schema.Set).Difference(old.(*schema.Set)).List() {
+			association := client.ObjectSchemaAssociation{FromObjectTypeId: objectTypeId, ToObjectTypeId: v.(string)}
+			if err := apiClient.CreateObjectSchemaAssociation(ctx, objectTypeId, &association); err != nil {
+				return failed(err)
+			}
+		}
+		if dissociated := old.(*schema.Set).Difference(new.(*schema.Set)); dissociated.Len() > 0 {
+			objectSchema, err := apiClient.GetObjectSchema(ctx, objectTypeId)
+			if err != nil {
+				return failed(err)
+			}
+			for _, association := range objectSchema.Associations {
+				if association.FromObjectTypeId != objectTypeId || !dissociated.Contains(association.ToObjectTypeId) {
+					continue
+				}
+				if err := apiClient.DeleteObjectSchemaAssociation(ctx, objectTypeId, association.Id); err != nil {
+					return failed(err)
+				}
+			}
+		}
+	}
+	for _, property := range removed {
+		if err := apiClient.DeleteProperty(ctx, objectTypeId, property.Name); err != nil {
+			return failed(err)
+		}
+	}
+	return resourceCustomObjectSchemaRead(ctx, d, m)
+}
+
+func resourceCustomObjectSchemaDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	if err := apiClient.DeleteObjectSchema(ctx, d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+// resourceCustomObjectSchemaImporter accepts the object type id or the fully
+// qualified name of the custom object.
+func resourceCustomObjectSchemaImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	diags := resourceCustomObjectSchemaRead(ctx, d, m)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("custom object %s does not exist", id)
+	}
+	d.SetId(d.Get("object_type_id").(string))
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceCustomObjectSchemaCustomizeDiff rejects property blocks sharing a
+// name and, when the object is created, display, required and searchable
+// properties that are not property blocks. Afterwards they may also refer to
+// properties managed with hubspot_property, but removing a property block
+// they still refer to is rejected.
+func resourceCustomObjectSchemaCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	names := make(map[string]bool)
+	for i, v := range d.Get("property").([]interface{}) {
+		property, ok := v.(map[string]interface{})
+		if !ok || !d.NewValueKnown(fmt.Sprintf("property.%d.name", i)) {
+			continue
+		}
+		name, _ := property["name"].(string)
+		if names[name] {
+			return fmt.Errorf("property %q is defined more than once", name)
+		}
+		names[name] = true
+		options, _ := property["option"].([]interface{})
+		if len(options) > 0 && d.NewValueKnown(fmt.Sprintf("property.%d.type", i)) && property["type"] != "enumeration" {
+			return fmt.Errorf("property %q: option blocks are only allowed on properties of type enumeration", name)
+		}
+	}
+	references := map[string][]interface{}{
+		"primary_display_property":     {d.Get("primary_display_property")},
+		"required_properties":          d.Get("required_properties").(*schema.Set).List(),
+		"searchable_properties":        d.Get("searchable_properties").(*schema.Set).List(),
+		"secondary_display_properties": d.Get("secondary_display_properties").([]interface{}),
+	}
+	keys := make([]string, 0, len(references))
+	for key := range references {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if d.Id() != "" {
+		return validateObjectSchemaPropertyRemovals(d, references, keys)
+	}
+	for _, key := range keys {
+		if !d.NewValueKnown(key) {
+			continue
+		}
+		for _, v := range references[key] {
+			if name, _ := v.(string); name != "" && !names[name] {
+				return fmt.Errorf("%s: %q is not one of the property blocks, which are the only properties of a new custom object", key, name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateObjectSchemaPropertyRemovals rejects the removal of a property
+// block the object still refers to in one of the keys of references.
+func validateObjectSchemaPropertyRemovals(d *schema.ResourceDiff, references map[string][]interface{}, keys []string) error {
+	old, _ := d.GetChange("property")
+	removed := make(map[string]bool)
+	for _, v := range old.([]interface{}) {
+		if property, ok := v.(map[string]interface{}); ok {
+			removed[property["name"].(string)] = true
+		}
+	}
+	for i, v := range d.Get("property").([]interface{}) {
+		property, ok := v.(map[string]interface{})
+		if !ok || !d.NewValueKnown(fmt.Sprintf("property.%d.name", i)) {
+			return nil
+		}
+		name, _ := property["name"].(string)
+		delete(removed, name)
+	}
+	for _, key := range keys {
+		if !d.NewValueKnown(key) {
+			continue
+		}
+		for _, v := range references[key] {
+			if name, _ := v.(string); removed[name] {
+				return fmt.Errorf("property %q cannot be removed while %s refers to it", name, key)
+			}
+		}
+	}
+	return nil
+}
+
+// expandObjectSchema returns the fields of the schema that can be updated.
+func expandObjectSchema(d *schema.ResourceData) client.ObjectSchema {
+	return client.ObjectSchema{
+		Name: d.Get("name").(string),
+		Labels: client.ObjectSchemaLabels{
+			Singular: d.Get("singular_label").(string),
+			Plural:   d.Get("plural_label").(string),
+		},
+		PrimaryDisplayProperty:     d.Get("primary_display_property").(string),
+		RequiredProperties:         expandStringSet(d.Get("required_properties").(*schema.Set)),
+		SearchableProperties:       expandStringSet(d.Get("searchable_properties").(*schema.Set)),
+		SecondaryDisplayProperties: expandStringList(d.Get("secondary_display_properties").([]interface{})),
+	}
+}
+
+func expandStringList(list []interface{}) []string {
+	values := make([]string, 0, len(list))
+	for _, v := range list {
+		values = append(values, v.(string))
+	}
+	return values
+}
+
+func expandObjectSchemaProperties(list []interface{}) []client.Property {
+	properties := make([]client.Property, 0, len(list))
+	for _, v := range list {
+		property := v.(map[string]interface{})
+		options, _ := property["option"].([]interface{})
+		properties = append(properties, client.Property{
+			Name:        property["name"].(string),
+			Label:       property["label"].(string),
+			Type:        property["type"].(string),
+			FieldType:   property["field_type"].(string),
+			GroupName:   property["group_name"].(string),
+			Description: property["description"].(string),
+			Options:     expandPropertyOptions(options),
+		})
+	}
+	return properties
+}
+
+func flattenObjectSchemaProperties(properties []client.Property) []interface{} {
+	list := make([]interface{}, 0, len(properties))
+	for _, property := range properties {
+		list = append(list, map[string]interface{}{
+			"name":        property.Name,
+			"label":       property.Label,
+			"type":        property.Type,
+			"field_type":  property.FieldType,
+			"group_name":  property.GroupName,
+			"description": property.Description,
+			"option":      flattenPropertyOptions(property.Options),
+		})
+	}
+	return list
+}
+
+// selectObjectSchemaProperties returns the properties read from HubSpot that
+// are known, in the same order, so that properties managed with
+// hubspot_property are left out. Without known properties, after an import,
+// every property but HubSpot's own hs_ properties is returned by name.
+func selectObjectSchemaProperties(properties, known []client.Property) []client.Property {
+	byName := make(map[string]client.Property, len(properties))
+	for _, property := range properties {
+		byName[property.Name] = property
+	}
+	var selected []client.Property
+	if len(known) == 0 {
+		for _, property := range properties {
+			if !strings.HasPrefix(property.Name, "hs_") {
+				selected = append(selected, property)
+			}
+		}
+		sort.Slice(selected, func(i, j int) bool {
+			return selected[i].Name < selected[j].Name
+		})
+	}
+	for _, property := range known {
+		if current, ok := byName[property.Name]; ok {
+			current.Options = orderPropertyOptions(current.Options, property.Options)
+			selected = append(selected, current)
+		}
+	}
+	return selected
+}
+
+// diffObjectSchemaProperties matches the old and new properties by name. A
+// changed property without group_name keeps its group and, like with
+// hubspot_property, its options when they did not change.
+func diffObjectSchemaProperties(old, new []client.Property) (added, changed, removed []client.Property) {
+	previous := make(map[string]client.Property, len(old))
+	for _, property := range old {
+		previous[property.Name] = property
+	}
+	for _, property := range new {
+		oldProperty, ok := previous[property.Name]
+		if !ok {
+			added = append(added, property)
+			continue
+		}
+		delete(previous, property.Name)
+		if property.GroupName == "" {
+			property.GroupName = oldProperty.GroupName
+		}
+		if reflect.DeepEqual(property, oldProperty) {
+			continue
+		}
+		if reflect.DeepEqual(property.Options, oldProperty.Options) {
+			property.Options = nil
+		} else {
+			log.Printf("[DEBUG] Updating options of property %s: %s", property.Name, describeOptionChanges(oldProperty.Options, property.Options))
+		}
+		changed = append(changed, property)
+	}
+	for _, property := range old {
+		if _, ok := previous[property.Name]; ok {
+			removed = append(removed, property)
+		}
+	}
+	return added, changed, removed
+}
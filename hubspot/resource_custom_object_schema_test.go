@@ -0,0 +1,269 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-hubspot/client"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccCustomObjectSchema_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckCustomObjectSchemaBasic("Cars", `"0-1"`, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("hubspot_custom_object_schema.car", "object_type_id"),
+					resource.TestCheckResourceAttrSet("hubspot_custom_object_schema.car", "fully_qualified_name"),
+					resource.TestCheckResourceAttr("hubspot_custom_object_schema.car", "property.#", "2"),
+					resource.TestCheckResourceAttrSet("hubspot_custom_object_schema.car", "property.0.group_name"),
+					resource.TestCheckResourceAttr("hubspot_custom_object_schema.car", "associated_object_types.#", "1"),
+				),
+			},
+			{
+				Config: testAccCheckCustomObjectSchemaBasic("Vehicles", `"0-2"`, `
+		property {
+			name       = "year"
+			label      = "Year"
+			type       = "number"
+			field_type = "number"
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_custom_object_schema.car", "plural_label", "Vehicles"),
+					resource.TestCheckResourceAttr("hubspot_custom_object_schema.car", "property.#", "3"),
+					resource.TestCheckResourceAttr("hubspot_custom_object_schema.car", "property.2.name", "year"),
+					resource.TestCheckResourceAttrPair("hubspot_custom_object_schema.car", "property.2.group_name", "hubspot_custom_object_schema.car", "property.0.group_name"),
+					resource.TestCheckTypeSetElemAttr("hubspot_custom_object_schema.car", "associated_object_types.*", "0-2"),
+				),
+			},
+			{
+				ResourceName:      "hubspot_custom_object_schema.car",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckCustomObjectSchemaBasic(pluralLabel, associatedObjectTypes, properties string) string {
+	return fmt.Sprintf(`
+	resource "hubspot_custom_object_schema" "car" {
+		name                     = "car"
+		singular_label           = "Car"
+		plural_label             = "%s"
+		primary_display_property = "model"
+		required_properties      = ["model"]
+		searchable_properties    = ["model", "vin"]
+		associated_object_types  = [%s]
+
+		property {
+			name       = "model"
+			label      = "Model"
+			type       = "string"
+			field_type = "text"
+		}
+		property {
+			name       = "vin"
+			label      = "VIN"
+			type       = "string"
+			field_type = "text"
+		}
+		%s
+	}
+	`, pluralLabel, associatedObjectTypes, properties)
+}
+
+func TestResourceCustomObjectSchemaDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "2-1001",
+		Attributes: map[string]string{
+			"id":                       "2-1001",
+			"name":                     "car",
+			"singular_label":           "Car",
+			"plural_label":             "Cars",
+			"primary_display_property": "model",
+			"property.#":               "2",
+			"property.0.name":          "model",
+			"property.0.label":         "Model",
+			"property.0.type":          "string",
+			"property.0.field_type":    "text",
+			"property.0.group_name":    "car_information",
+			"property.0.option.#":      "0",
+			"property.1.name":          "vin",
+			"property.1.label":         "VIN",
+			"property.1.type":          "string",
+			"property.1.field_type":    "text",
+			"property.1.group_name":    "car_information",
+			"property.1.option.#":      "0",
+		},
+	}
+	testCases := []struct {
+		testName      string
+		state         *terraform.InstanceState
+		config        map[string]interface{}
+		expectErr     bool
+		expectReplace bool
+	}{
+		{
+			testName: "new object",
+			config:   map[string]interface{}{},
+		},
+		{
+			testName: "new object displaying a property that is not a property block",
+			config: map[string]interface{}{
+				"secondary_display_properties": []interface{}{"year"},
+			},
+			expectErr: true,
+		},
+		{
+			testName: "duplicate property",
+			config: map[string]interface{}{
+				"property": []interface{}{
+					map[string]interface{}{"name": "model", "label": "Model", "type": "string", "field_type": "text"},
+					map[string]interface{}{"name": "model", "label": "Model name", "type": "string", "field_type": "text"},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			testName: "labels and display properties are patched",
+			state:    state,
+			config: map[string]interface{}{
+				"plural_label":                 "Vehicles",
+				"secondary_display_properties": []interface{}{"year"},
+			},
+		},
+		{
+			testName: "added property is patched",
+			state:    state,
+			config: map[string]interface{}{
+				"property": []interface{}{
+					map[string]interface{}{"name": "model", "label": "Model", "type": "string", "field_type": "text"},
+					map[string]interface{}{"name": "year", "label": "Year", "type": "number", "field_type": "number"},
+				},
+			},
+		},
+		{
+			testName: "changed type and field_type are patched",
+			state:    state,
+			config: map[string]interface{}{
+				"property": []interface{}{
+					map[string]interface{}{"name": "model", "label": "Model", "type": "string", "field_type": "textarea"},
+					map[string]interface{}{"name": "vin", "label": "VIN", "type": "number", "field_type": "number"},
+				},
+			},
+		},
+		{
+			testName: "removed property is deleted",
+			state:    state,
+			config: map[string]interface{}{
+				"property": []interface{}{
+					map[string]interface{}{"name": "model", "label": "Model", "type": "string", "field_type": "text"},
+				},
+			},
+		},
+		{
+			testName: "removed property still searchable is rejected",
+			state:    state,
+			config: map[string]interface{}{
+				"searchable_properties": []interface{}{"vin"},
+				"property": []interface{}{
+					map[string]interface{}{"name": "model", "label": "Model", "type": "string", "field_type": "text"},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			testName: "removed property still displayed is rejected",
+			state:    state,
+			config: map[string]interface{}{
+				"primary_display_property": "vin",
+				"property": []interface{}{
+					map[string]interface{}{"name": "model", "label": "Model", "type": "string", "field_type": "text"},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			testName: "rename replaces the object",
+			state:    state,
+			config: map[string]interface{}{
+				"name": "vehicle",
+			},
+			expectReplace: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			config := map[string]interface{}{
+				"name":                     "car",
+				"singular_label":           "Car",
+				"plural_label":             "Cars",
+				"primary_display_property": "model",
+				"property": []interface{}{
+					map[string]interface{}{"name": "model", "label": "Model", "type": "string", "field_type": "text"},
+					map[string]interface{}{"name": "vin", "label": "VIN", "type": "string", "field_type": "text"},
+				},
+			}
+			for k, v := range tc.config {
+				config[k] = v
+			}
+			diff, err := resourceCustomObjectSchema().Diff(context.Background(), tc.state, terraform.NewResourceConfigRaw(config), nil)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.state != nil {
+				assert.Equal(t, tc.expectReplace, diff != nil && diff.RequiresNew())
+			}
+		})
+	}
+}
+
+func TestDiffObjectSchemaProperties(t *testing.T) {
+	old := []client.Property{
+		{Name: "model", Label: "Model", Type: "string", FieldType: "text", GroupName: "car_information"},
+		{Name: "vin", Label: "VIN", Type: "string", FieldType: "text", GroupName: "car_information"},
+		{Name: "color", Label: "Color", Type: "enumeration", FieldType: "select", GroupName: "car_information", Options: []client.PropertyOption{{Label: "Red", Value: "red"}}},
+	}
+	new := []client.Property{
+		{Name: "year", Label: "Year", Type: "number", FieldType: "number"},
+		{Name: "model", Label: "Model name", Type: "string", FieldType: "textarea"},
+		{Name: "color", Label: "Colour", Type: "enumeration", FieldType: "select", Options: []client.PropertyOption{{Label: "Red", Value: "red"}}},
+	}
+	added, changed, removed := diffObjectSchemaProperties(old, new)
+	assert.Equal(t, []client.Property{new[0]}, added)
+	assert.Equal(t, []client.Property{
+		{Name: "model", Label: "Model name", Type: "string", FieldType: "textarea", GroupName: "car_information"},
+		// Unchanged options are left alone.
+		{Name: "color", Label: "Colour", Type: "enumeration", FieldType: "select", GroupName: "car_information"},
+	}, changed)
+	assert.Equal(t, []client.Property{old[1]}, removed)
+}
+
+func TestSelectObjectSchemaProperties(t *testing.T) {
+	properties := []client.Property{
+		{Name: "hs_object_id"},
+		{Name: "vin"},
+		{Name: "model"},
+		{Name: "mileage"},
+	}
+	var names []string
+	for _, property := range selectObjectSchemaProperties(properties, []client.Property{{Name: "vin"}, {Name: "year"}, {Name: "model"}}) {
+		names = append(names, property.Name)
+	}
+	assert.Equal(t, []string{"vin", "model"}, names, "only known properties are kept, in their order")
+
+	names = nil
+	for _, property := range selectObjectSchemaProperties(properties, nil) {
+		names = append(names, property.Name)
+	}
+	assert.Equal(t, []string{"mileage", "model", "vin"}, names, "an import reads every property but HubSpot's own")
+}
@@ -0,0 +1,397 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourcePipeline() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePipelineCreate,
+		ReadContext:   resourcePipelineRead,
+		UpdateContext: resourcePipelineUpdate,
+		DeleteContext: resourcePipelineDelete,
+		CustomizeDiff: resourcePipelineCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineImporter,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"object_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"label": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"display_order": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"stage": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"label": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"display_order": &schema.Schema{
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          -1,
+							DiffSuppressFunc: suppressDisplayOrderPositionDiff,
+						},
+						"probability":  stageProbabilitySchema(),
+						"ticket_state": stageTicketStateSchema(),
+					},
+				},
+			},
+		},
+	}
+}
+
+func stageProbabilitySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeFloat,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validation.FloatBetween(0, 1),
+	}
+}
+
+func stageTicketStateSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validation.StringInSlice([]string{"OPEN", "CLOSED"}, false),
+	}
+}
+
+func resourcePipelineCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	objectType := d.Get("object_type").(string)
+	requested := expandPipelineStages(objectType, d.Get("stage").([]interface{}))
+	pipeline := client.Pipeline{
+		Label:        d.Get("label").(string),
+		DisplayOrder: d.Get("display_order").(int),
+		Stages:       requested,
+	}
+	if err := apiClient.CreatePipeline(ctx, objectType, &pipeline); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(objectTypeId(objectType, pipeline.Id))
+	// Record the ids of the stages in the configured order, which the read
+	// keeps.
+	stages, _ := matchPipelineStages(pipeline.Stages, requested)
+	d.Set("stage", flattenPipelineStages(stages))
+	return resourcePipelineRead(ctx, d, m)
+}
+
+func resourcePipelineRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, pipelineId, err := parseObjectTypeId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	pipeline, err := apiClient.GetPipeline(ctx, objectType, pipelineId)
+	if err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
+	known := expandPipelineStages(objectType, d.Get("stage").([]interface{}))
+	d.Set("object_type", objectType)
+	d.Set("label", pipeline.Label)
+	d.Set("display_order", pipeline.DisplayOrder)
+	d.Set("stage", flattenPipelineStages(orderPipelineStages(pipeline.Stages, known)))
+	return diags
+}
+
+// resourcePipelineUpdate updates the pipeline and then its stages one by one,
+// so that the stages and their records are kept. Removed stages are deleted
+// last: HubSpot refuses to delete stages that still have records.
+func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	objectType, pipelineId, err := parseObjectTypeId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if d.HasChanges("label", "display_order") {
+		pipeline := client.Pipeline{
+			Id:           pipelineId,
+			Label:        d.Get("label").(string),
+			DisplayOrder: d.Get("display_order").(int),
+		}
+		if err := apiClient.UpdatePipeline(ctx, objectType, &pipeline); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if d.HasChange("stage") {
+		old, new := d.GetChange("stage")
+		oldStages := expandPipelineStages(objectType, old.([]interface{}))
+		stages, removed := matchPipelineStages(oldStages, expandPipelineStages(objectType, new.([]interface{})))
+		previous := make(map[string]client.PipelineStage, len(oldStages))
+		for _, stage := range oldStages {
+			previous[stage.Id] = stage
+		}
+		for i := range stages {
+			stage := &stages[i]
+			if stage.Id == "" {
+				err = apiClient.CreatePipelineStage(ctx, objectType, pipelineId, stage)
+			} else if !reflect.DeepEqual(*stage, previous[stage.Id]) {
+				err = apiClient.UpdatePipelineStage(ctx, objectType, pipelineId, stage)
+			}
+			if err != nil {
+				d.Set("stage", flattenPipelineStages(stages))
+				return append(resourcePipelineRead(ctx, d, m), diag.FromErr(err)...)
+			}
+		}
+		// Record the ids of the stages in the configured order, which the
+		// read keeps.
+		d.Set("stage", flattenPipelineStages(stages))
+		for _, stage := range removed {
+			if err := apiClient.DeletePipelineStage(ctx, objectType, pipelineId, stage.Id); err != nil {
+				// Keep the stages HubSpot refused to delete in the state.
+				return append(resourcePipelineRead(ctx, d, m), stageDeleteDiagnostics(err, stage)...)
+			}
+		}
+	}
+	return resourcePipelineRead(ctx, d, m)
+}
+
+func resourcePipelineDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, pipelineId, err := parseObjectTypeId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := apiClient.DeletePipeline(ctx, objectType, pipelineId); err != nil {
+		if isDeleteRejected(err) {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Unable to delete pipeline %q: %s", d.Get("label").(string), err),
+				Detail:   "HubSpot does not delete pipelines with records in their stages. If this pipeline has records, move them to another pipeline and apply again.",
+			}}
+		}
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func resourcePipelineImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if _, _, err := parseObjectTypeId(id); err != nil {
+		return nil, err
+	}
+	diags := resourcePipelineRead(ctx, d, m)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("pipeline %s does not exist", id)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourcePipelineCustomizeDiff rejects stage arguments that do not apply to
+// the object type of the pipeline.
+func resourcePipelineCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("object_type") {
+		return nil
+	}
+	objectType := d.Get("object_type").(string)
+	for i := range d.Get("stage").([]interface{}) {
+		prefix := fmt.Sprintf("stage.%d.", i)
+		if err := validateStageArguments(objectType, d.Get(prefix+"probability").(float64), d.Get(prefix+"ticket_state").(string)); err != nil {
+			return fmt.Errorf("stage %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// pipelineObjectType returns the name of the object type of a pipeline, which
+// may also be given by its object type id, such as 0-3 for deals.
+func pipelineObjectType(objectType string) string {
+	switch objectType {
+	case "0-3":
+		return "deals"
+	case "0-5":
+		return "tickets"
+	}
+	return objectType
+}
+
+// validateStageArguments reports a probability set on a stage that is not a
+// deal stage, or a ticket_state set on a stage that is not a ticket stage.
+func validateStageArguments(objectType string, probability float64, ticketState string) error {
+	objectType = pipelineObjectType(objectType)
+	if probability != 0 && objectType != "deals" {
+		return fmt.Errorf("probability only applies to deal pipelines")
+	}
+	if ticketState != "" && objectType != "tickets" {
+		return fmt.Errorf("ticket_state only applies to ticket pipelines")
+	}
+	return nil
+}
+
+// expandStageMetadata returns the metadata HubSpot requires for stages of the
+// object type: a probability for deals and a ticket state, OPEN unless set,
+// for tickets.
+func expandStageMetadata(objectType string, probability float64, ticketState string) map[string]string {
+	switch pipelineObjectType(objectType) {
+	case "deals":
+		return map[string]string{"probability": strconv.FormatFloat(probability, 'f', -1, 64)}
+	case "tickets":
+		if ticketState == "" {
+			ticketState = "OPEN"
+		}
+		return map[string]string{"ticketState": ticketState}
+	}
+	return map[string]string{}
+}
+
+func expandPipelineStages(objectType string, list []interface{}) []client.PipelineStage {
+	stages := make([]client.PipelineStage, 0, len(list))
+	for i, v := range list {
+		stage := v.(map[string]interface{})
+		displayOrder := stage["display_order"].(int)
+		if displayOrder == -1 {
+			displayOrder = i
+		}
+		id, _ := stage["id"].(string)
+		stages = append(stages, client.PipelineStage{
+			Id:           id,
+			Label:        stage["label"].(string),
+			DisplayOrder: displayOrder,
+			Metadata:     expandStageMetadata(objectType, stage["probability"].(float64), stage["ticket_state"].(string)),
+		})
+	}
+	return stages
+}
+
+func flattenPipelineStages(stages []client.PipelineStage) []interface{} {
+	list := make([]interface{}, 0, len(stages))
+	for _, stage := range stages {
+		probability, _ := strconv.ParseFloat(stage.Metadata["probability"], 64)
+		list = append(list, map[string]interface{}{
+			"id":            stage.Id,
+			"label":         stage.Label,
+			"display_order": stage.DisplayOrder,
+			"probability":   probability,
+			"ticket_state":  stage.Metadata["ticketState"],
+		})
+	}
+	return list
+}
+
+// orderPipelineStages orders the stages read from HubSpot like the known
+// stages with the same ids, followed by the other stages by display order.
+func orderPipelineStages(stages, known []client.PipelineStage) []client.PipelineStage {
+	position := make(map[string]int, len(known))
+	for i, stage := range known {
+		if stage.Id != "" {
+			position[stage.Id] = i
+		}
+	}
+	ordered := append([]client.PipelineStage(nil), stages...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, iKnown := position[ordered[i].Id]
+		pj, jKnown := position[ordered[j].Id]
+		switch {
+		case iKnown && jKnown:
+			return pi < pj
+		case iKnown != jKnown:
+			return iKnown
+		default:
+			return ordered[i].DisplayOrder < ordered[j].DisplayOrder
+		}
+	})
+	return ordered
+}
+
+// matchPipelineStages assigns the ids of the old stages to the new stages
+// they correspond to: first the stage with the same label, otherwise the
+// stage at the same position, which is then relabeled. It returns the new
+// stages, those without id to be created, and the old stages to be removed.
+func matchPipelineStages(old, new []client.PipelineStage) ([]client.PipelineStage, []client.PipelineStage) {
+	matched := make([]bool, len(old))
+	stages := make([]client.PipelineStage, len(new))
+	for i, stage := range new {
+		stage.Id = ""
+		for j := range old {
+			if !matched[j] && old[j].Label == stage.Label {
+				matched[j] = true
+				stage.Id = old[j].Id
+				break
+			}
+		}
+		stages[i] = stage
+	}
+	for i := range stages {
+		if stages[i].Id == "" && i < len(old) && !matched[i] {
+			matched[i] = true
+			stages[i].Id = old[i].Id
+		}
+	}
+	var removed []client.PipelineStage
+	for j, stage := range old {
+		if !matched[j] {
+			removed = append(removed, stage)
+		}
+	}
+	return stages, removed
+}
+
+// isDeleteRejected reports whether HubSpot rejected the deletion of a stage
+// or pipeline, which it does for stages that still have records. HubSpot's
+// errors do not tell that apart from other rejections, so the error is
+// reported as it is, with a hint.
+func isDeleteRejected(err error) bool {
+	switch client.StatusCode(err) {
+	case http.StatusBadRequest, http.StatusConflict:
+		return true
+	}
+	return false
+}
+
+func stageDeleteDiagnostics(err error, stage client.PipelineStage) diag.Diagnostics {
+	if !isDeleteRejected(err) {
+		return diag.FromErr(err)
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Unable to delete pipeline stage %q (%s): %s", stage.Label, stage.Id, err),
+		Detail:   "HubSpot does not delete stages that still have records. If this stage has records, move them to another stage and apply again.",
+	}}
+}
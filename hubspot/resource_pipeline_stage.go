@@ -0,0 +1,169 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourcePipelineStage manages a single stage, typically of a pipeline that
+// is not managed by Terraform such as the default deal pipeline.
+func resourcePipelineStage() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePipelineStageCreate,
+		ReadContext:   resourcePipelineStageRead,
+		UpdateContext: resourcePipelineStageUpdate,
+		DeleteContext: resourcePipelineStageDelete,
+		CustomizeDiff: resourcePipelineStageCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineStageImporter,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"object_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"pipeline_id": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"stage_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"label": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"display_order": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"probability":  stageProbabilitySchema(),
+			"ticket_state": stageTicketStateSchema(),
+		},
+	}
+}
+
+func resourcePipelineStageCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	objectType := d.Get("object_type").(string)
+	pipelineId := d.Get("pipeline_id").(string)
+	stage := expandPipelineStage(d)
+	if err := apiClient.CreatePipelineStage(ctx, objectType, pipelineId, &stage); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(pipelineStageId(objectType, pipelineId, stage.Id))
+	return resourcePipelineStageRead(ctx, d, m)
+}
+
+func resourcePipelineStageRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, pipelineId, stageId, err := parsePipelineStageId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	stage, err := apiClient.GetPipelineStage(ctx, objectType, pipelineId, stageId)
+	if err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
+	probability, _ := strconv.ParseFloat(stage.Metadata["probability"], 64)
+	d.Set("object_type", objectType)
+	d.Set("pipeline_id", pipelineId)
+	d.Set("stage_id", stage.Id)
+	d.Set("label", stage.Label)
+	d.Set("display_order", stage.DisplayOrder)
+	d.Set("probability", probability)
+	d.Set("ticket_state", stage.Metadata["ticketState"])
+	return diags
+}
+
+func resourcePipelineStageUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	stage := expandPipelineStage(d)
+	stage.Id = d.Get("stage_id").(string)
+	if err := apiClient.UpdatePipelineStage(ctx, d.Get("object_type").(string), d.Get("pipeline_id").(string), &stage); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourcePipelineStageRead(ctx, d, m)
+}
+
+func resourcePipelineStageDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, pipelineId, stageId, err := parsePipelineStageId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := apiClient.DeletePipelineStage(ctx, objectType, pipelineId, stageId); err != nil {
+		return stageDeleteDiagnostics(err, client.PipelineStage{Id: stageId, Label: d.Get("label").(string)})
+	}
+	d.SetId("")
+	return diags
+}
+
+func resourcePipelineStageImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if _, _, _, err := parsePipelineStageId(id); err != nil {
+		return nil, err
+	}
+	diags := resourcePipelineStageRead(ctx, d, m)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("pipeline stage %s does not exist", id)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePipelineStageCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("object_type") {
+		return nil
+	}
+	return validateStageArguments(d.Get("object_type").(string), d.Get("probability").(float64), d.Get("ticket_state").(string))
+}
+
+// pipelineStageId returns the ID of a stage resource,
+// objectType/pipelineId/stageId.
+func pipelineStageId(objectType, pipelineId, stageId string) string {
+	return objectType + "/" + pipelineId + "/" + stageId
+}
+
+func parsePipelineStageId(id string) (objectType, pipelineId, stageId string, err error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("unexpected ID %q, expected objectType/pipelineId/stageId such as deals/default/appointmentscheduled", id)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+func expandPipelineStage(d *schema.ResourceData) client.PipelineStage {
+	return client.PipelineStage{
+		Label:        d.Get("label").(string),
+		DisplayOrder: d.Get("display_order").(int),
+		Metadata:     expandStageMetadata(d.Get("object_type").(string), d.Get("probability").(float64), d.Get("ticket_state").(string)),
+	}
+}
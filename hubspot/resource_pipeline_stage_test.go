@@ -0,0 +1,95 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-hubspot/client"
+	"terraform-provider-hubspot/hubspottest"
+	"terraform-provider-hubspot/token"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccPipelineStage_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckPipelineStageBasic("Contract sent", 0.8),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("hubspot_pipeline_stage.contract", "stage_id"),
+					resource.TestCheckResourceAttr("hubspot_pipeline_stage.contract", "probability", "0.8"),
+				),
+			},
+			{
+				Config: testAccCheckPipelineStageBasic("Contract signed", 0.9),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_pipeline_stage.contract", "label", "Contract signed"),
+					resource.TestCheckResourceAttr("hubspot_pipeline_stage.contract", "probability", "0.9"),
+				),
+			},
+			{
+				ResourceName:      "hubspot_pipeline_stage.contract",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckPipelineStageBasic(label string, probability float64) string {
+	return fmt.Sprintf(`
+	resource "hubspot_pipeline_stage" "contract" {
+		object_type   = "deals"
+		pipeline_id   = "default"
+		label         = "%s"
+		display_order = 5
+		probability   = %g
+	}
+	`, label, probability)
+}
+
+func TestResourcePipelineStageDelete_records(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	pipeline := server.AddPipeline("deals", hubspottest.Pipeline{
+		Label: "Renewals",
+		Stages: []hubspottest.PipelineStage{
+			{Label: "Negotiation", Metadata: map[string]string{"probability": "0.5"}},
+		},
+	})
+	stageId := pipeline.Stages[0].Id
+	server.SetStageRecords(stageId, 1)
+	apiClient := client.NewClient(token.StaticSource(hubspottest.AccessToken))
+	apiClient.HostURL = server.URL
+
+	d := schema.TestResourceDataRaw(t, resourcePipelineStage().Schema, map[string]interface{}{
+		"object_type": "deals",
+		"pipeline_id": pipeline.Id,
+		"label":       "Negotiation",
+	})
+	d.SetId(pipelineStageId("deals", pipeline.Id, stageId))
+	diags := resourcePipelineStageDelete(context.Background(), d, apiClient)
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, `Unable to delete pipeline stage "Negotiation" (`+stageId+`)`)
+		assert.Contains(t, diags[0].Summary, "cannot be deleted because it has 1 records", "HubSpot's error is reported as it is")
+	}
+	assert.NotEmpty(t, d.Id())
+
+	server.SetStageRecords(stageId, 0)
+	assert.False(t, resourcePipelineStageDelete(context.Background(), d, apiClient).HasError())
+	assert.Empty(t, d.Id())
+}
+
+func TestParsePipelineStageId(t *testing.T) {
+	objectType, pipelineId, stageId, err := parsePipelineStageId("deals/default/appointmentscheduled")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deals", "default", "appointmentscheduled"}, []string{objectType, pipelineId, stageId})
+	for _, id := range []string{"deals/default", "deals//appointmentscheduled", "deals/default/a/b"} {
+		_, _, _, err := parsePipelineStageId(id)
+		assert.Error(t, err, id)
+	}
+}
@@ -0,0 +1,229 @@
+package hubspot
+
+import (
+	"fmt"
+	"regexp"
+	"terraform-provider-hubspot/client"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccPipeline_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckPipelineBasic("Renewals", `
+		stage {
+			label       = "Negotiation"
+			probability = 0.5
+		}
+		stage {
+			label       = "Won"
+			probability = 1
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "label", "Renewals"),
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "stage.#", "2"),
+					resource.TestCheckResourceAttrSet("hubspot_pipeline.renewals", "stage.0.id"),
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "stage.1.display_order", "1"),
+				),
+			},
+			{
+				Config: testAccCheckPipelineBasic("Renewal deals", `
+		stage {
+			label       = "Qualified"
+			probability = 0.2
+		}
+		stage {
+			label       = "Negotiation"
+			probability = 0.6
+		}
+		stage {
+			label       = "Closed won"
+			probability = 1
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "label", "Renewal deals"),
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "stage.#", "3"),
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "stage.1.label", "Negotiation"),
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "stage.1.probability", "0.6"),
+					resource.TestCheckResourceAttr("hubspot_pipeline.renewals", "stage.2.label", "Closed won"),
+				),
+			},
+			{
+				ResourceName:      "hubspot_pipeline.renewals",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckPipelineBasic(label, stages string) string {
+	return fmt.Sprintf(`
+	resource "hubspot_pipeline" "renewals" {
+		object_type = "deals"
+		label       = "%s"
+		%s
+	}
+	`, label, stages)
+}
+
+// TestAccPipeline_StageWithRecords puts records in a stage of the fake server,
+// so it does not run against the real API.
+func TestAccPipeline_StageWithRecords(t *testing.T) {
+	if testAccLive() {
+		t.Skip("needs records in a pipeline stage of the fake server")
+	}
+	var closedStageId string
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckPipelineTickets(`
+		stage {
+			label = "New"
+		}
+		stage {
+			label        = "Closed"
+			ticket_state = "CLOSED"
+		}`),
+				Check: func(s *terraform.State) error {
+					closedStageId = s.RootModule().Resources["hubspot_pipeline.support"].Primary.Attributes["stage.1.id"]
+					testAccServer.SetStageRecords(closedStageId, 2)
+					return nil
+				},
+			},
+			{
+				Config: testAccCheckPipelineTickets(`
+		stage {
+			label = "New"
+		}`),
+				ExpectError: regexp.MustCompile(`Unable to delete pipeline stage "Closed" \(\d+\): .*has 2 records`),
+			},
+			{
+				PreConfig: func() {
+					testAccServer.SetStageRecords(closedStageId, 0)
+				},
+				Config: testAccCheckPipelineTickets(`
+		stage {
+			label = "New"
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_pipeline.support", "stage.#", "1"),
+					resource.TestCheckResourceAttr("hubspot_pipeline.support", "stage.0.ticket_state", "OPEN"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPipelineTickets(stages string) string {
+	return fmt.Sprintf(`
+	resource "hubspot_pipeline" "support" {
+		object_type = "tickets"
+		label       = "Support"
+		%s
+	}
+	`, stages)
+}
+
+func TestMatchPipelineStages(t *testing.T) {
+	old := []client.PipelineStage{
+		{Id: "1", Label: "Negotiation"},
+		{Id: "2", Label: "Won"},
+		{Id: "3", Label: "Lost"},
+	}
+	new := []client.PipelineStage{
+		{Label: "Qualified"},
+		{Label: "Negotiation"},
+		{Label: "Closed won"},
+		{Label: "On hold"},
+	}
+	stages, removed := matchPipelineStages(old, new)
+	var ids []string
+	for _, stage := range stages {
+		ids = append(ids, stage.Id)
+	}
+	// Negotiation keeps its stage, Lost at the same position is relabeled
+	// Closed won, Qualified and On hold are created and Won is removed.
+	assert.Equal(t, []string{"", "1", "3", ""}, ids)
+	assert.Equal(t, []client.PipelineStage{{Id: "2", Label: "Won"}}, removed)
+}
+
+func TestOrderPipelineStages(t *testing.T) {
+	stages := []client.PipelineStage{
+		{Id: "1", DisplayOrder: 0},
+		{Id: "2", DisplayOrder: 1},
+		{Id: "3", DisplayOrder: 2},
+		{Id: "4", DisplayOrder: 1},
+	}
+	known := []client.PipelineStage{{Id: "3"}, {Id: "1"}, {Id: ""}}
+	var ids []string
+	for _, stage := range orderPipelineStages(stages, known) {
+		ids = append(ids, stage.Id)
+	}
+	assert.Equal(t, []string{"3", "1", "2", "4"}, ids)
+}
+
+func TestValidateStageArguments(t *testing.T) {
+	assert.NoError(t, validateStageArguments("deals", 0.5, ""))
+	assert.NoError(t, validateStageArguments("tickets", 0, "CLOSED"))
+	assert.Error(t, validateStageArguments("tickets", 0.5, ""))
+	assert.Error(t, validateStageArguments("deals", 0, "OPEN"))
+	assert.Equal(t, map[string]string{"probability": "0.25"}, expandStageMetadata("deals", 0.25, ""))
+	assert.Equal(t, map[string]string{"ticketState": "OPEN"}, expandStageMetadata("tickets", 0, ""))
+
+	// Pipelines may also be given the object type id of deals or tickets.
+	assert.NoError(t, validateStageArguments("0-3", 0.5, ""))
+	assert.NoError(t, validateStageArguments("0-5", 0, "CLOSED"))
+	assert.Error(t, validateStageArguments("0-5", 0.5, ""))
+	assert.Error(t, validateStageArguments("0-3", 0, "OPEN"))
+	assert.Equal(t, map[string]string{"probability": "0.25"}, expandStageMetadata("0-3", 0.25, ""))
+	assert.Equal(t, map[string]string{"ticketState": "OPEN"}, expandStageMetadata("0-5", 0, ""))
+}
+
+func TestStageDeleteDiagnostics(t *testing.T) {
+	stage := client.PipelineStage{Id: "1234", Label: "Closed"}
+	testCases := []struct {
+		testName        string
+		err             error
+		expectedSummary string
+		expectHint      bool
+	}{
+		{
+			testName:        "rejected deletion",
+			err:             &client.APIError{StatusCode: 400, Category: "VALIDATION_ERROR", Message: "Stage 1234 cannot be deleted"},
+			expectedSummary: `Unable to delete pipeline stage "Closed" (1234): Bad Request, StatusCode = 400, Category = VALIDATION_ERROR: Stage 1234 cannot be deleted`,
+			expectHint:      true,
+		},
+		{
+			testName:        "conflict",
+			err:             &client.APIError{StatusCode: 409, Status: "Conflict", Message: "Stage is in use"},
+			expectedSummary: `Unable to delete pipeline stage "Closed" (1234): Conflict, StatusCode = 409: Stage is in use`,
+			expectHint:      true,
+		},
+		{
+			testName:        "server error",
+			err:             &client.APIError{StatusCode: 500, Message: "Stage has records"},
+			expectedSummary: "Internal Server Error, StatusCode = 500: Stage has records",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			diags := stageDeleteDiagnostics(tc.err, stage)
+			if len(diags) != 1 || diags[0].Summary != tc.expectedSummary {
+				t.Fatalf("expected %q, got %v", tc.expectedSummary, diags)
+			}
+			if hint := diags[0].Detail != ""; hint != tc.expectHint {
+				t.Fatalf("expected a hint: %t, got %q", tc.expectHint, diags[0].Detail)
+			}
+		})
+	}
+}
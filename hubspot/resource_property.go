@@ -0,0 +1,355 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceProperty() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePropertyCreate,
+		CustomizeDiff: resourcePropertyCustomizeDiff,
+		ReadContext:   resourcePropertyRead,
+		UpdateContext: resourcePropertyUpdate,
+		DeleteContext: resourcePropertyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePropertyImporter,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"object_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"label": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"bool", "enumeration", "date", "datetime", "string", "number"}, false),
+			},
+			"field_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"group_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"option": propertyOptionSchema(),
+			"calculation_formula": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"hidden": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"form_field": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+// propertyOptionSchema is the schema of the options of an enumeration
+// property.
+func propertyOptionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"label": &schema.Schema{
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"value": &schema.Schema{
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"description": &schema.Schema{
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"display_order": &schema.Schema{
+					Type:             schema.TypeInt,
+					Optional:         true,
+					Default:          -1,
+					DiffSuppressFunc: suppressDisplayOrderPositionDiff,
+				},
+				"hidden": &schema.Schema{
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+			},
+		},
+	}
+}
+
+func resourcePropertyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	objectType := d.Get("object_type").(string)
+	property := expandProperty(d)
+	if err := apiClient.CreateProperty(ctx, objectType, &property); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(objectTypeId(objectType, property.Name))
+	return resourcePropertyRead(ctx, d, m)
+}
+
+func resourcePropertyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, name, err := parseObjectTypeId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	property, err := apiClient.GetProperty(ctx, objectType, name)
+	if err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("object_type", objectType)
+	d.Set("name", property.Name)
+	d.Set("label", property.Label)
+	d.Set("type", property.Type)
+	d.Set("field_type", property.FieldType)
+	d.Set("group_name", property.GroupName)
+	d.Set("description", property.Description)
+	d.Set("option", flattenPropertyOptions(orderPropertyOptions(property.Options, expandPropertyOptions(d.Get("option").([]interface{})))))
+	d.Set("calculation_formula", property.CalculationFormula)
+	d.Set("hidden", property.Hidden)
+	d.Set("form_field", property.FormField)
+	return diags
+}
+
+func resourcePropertyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	property := expandProperty(d)
+	if d.HasChange("option") {
+		old, _ := d.GetChange("option")
+		log.Printf("[DEBUG] Updating options of property %s: %s", d.Id(), describeOptionChanges(expandPropertyOptions(old.([]interface{})), property.Options))
+	} else {
+		// Leave the options, and any change HubSpot made to them, alone.
+		property.Options = nil
+	}
+	if err := apiClient.UpdateProperty(ctx, d.Get("object_type").(string), &property); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourcePropertyRead(ctx, d, m)
+}
+
+func resourcePropertyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, name, err := parseObjectTypeId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := apiClient.DeleteProperty(ctx, objectType, name); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func resourcePropertyImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if _, _, err := parseObjectTypeId(id); err != nil {
+		return nil, err
+	}
+	diags := resourcePropertyRead(ctx, d, m)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("property %s does not exist", id)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// objectTypeId returns the ID of a resource scoped to an object type, such
+// as a property, in the objectType/name format.
+func objectTypeId(objectType, name string) string {
+	return objectType + "/" + name
+}
+
+func parseObjectTypeId(id string) (objectType, name string, err error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected ID %q, expected objectType/name such as contacts/favorite_color", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+func expandProperty(d *schema.ResourceData) client.Property {
+	return client.Property{
+		Name:               d.Get("name").(string),
+		Label:              d.Get("label").(string),
+		Type:               d.Get("type").(string),
+		FieldType:          d.Get("field_type").(string),
+		GroupName:          d.Get("group_name").(string),
+		Description:        d.Get("description").(string),
+		Options:            expandPropertyOptions(d.Get("option").([]interface{})),
+		CalculationFormula: d.Get("calculation_formula").(string),
+		Hidden:             d.Get("hidden").(bool),
+		FormField:          d.Get("form_field").(bool),
+	}
+}
+
+// expandPropertyOptions returns the configured options. An option without
+// display_order is displayed at its position in the list.
+func expandPropertyOptions(list []interface{}) []client.PropertyOption {
+	options := make([]client.PropertyOption, 0, len(list))
+	for i, v := range list {
+		option := v.(map[string]interface{})
+		displayOrder := option["display_order"].(int)
+		if displayOrder == -1 {
+			displayOrder = i
+		}
+		options = append(options, client.PropertyOption{
+			Label:        option["label"].(string),
+			Value:        option["value"].(string),
+			Description:  option["description"].(string),
+			DisplayOrder: displayOrder,
+			Hidden:       option["hidden"].(bool),
+		})
+	}
+	return options
+}
+
+func flattenPropertyOptions(options []client.PropertyOption) []interface{} {
+	list := make([]interface{}, 0, len(options))
+	for _, option := range options {
+		list = append(list, map[string]interface{}{
+			"label":         option.Label,
+			"value":         option.Value,
+			"description":   option.Description,
+			"display_order": option.DisplayOrder,
+			"hidden":        option.Hidden,
+		})
+	}
+	return list
+}
+
+// orderPropertyOptions orders the options read from HubSpot like the known
+// options with the same values, followed by the other options by display
+// order. As options are compared by position, this keeps the plan to the
+// options that were actually added, removed or changed.
+func orderPropertyOptions(options, known []client.PropertyOption) []client.PropertyOption {
+	position := make(map[string]int, len(known))
+	for i, option := range known {
+		position[option.Value] = i
+	}
+	ordered := append([]client.PropertyOption(nil), options...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, iKnown := position[ordered[i].Value]
+		pj, jKnown := position[ordered[j].Value]
+		switch {
+		case iKnown && jKnown:
+			return pi < pj
+		case iKnown != jKnown:
+			return iKnown
+		default:
+			return ordered[i].DisplayOrder < ordered[j].DisplayOrder
+		}
+	})
+	return ordered
+}
+
+// describeOptionChanges summarizes the options added, removed and changed
+// between old and new, matching options by value.
+func describeOptionChanges(old, new []client.PropertyOption) string {
+	oldOptions := make(map[string]client.PropertyOption, len(old))
+	for _, option := range old {
+		oldOptions[option.Value] = option
+	}
+	var added, changed []string
+	for _, option := range new {
+		previous, ok := oldOptions[option.Value]
+		switch {
+		case !ok:
+			added = append(added, option.Value)
+		case previous != option:
+			changed = append(changed, option.Value)
+		}
+		delete(oldOptions, option.Value)
+	}
+	removed := make([]string, 0, len(oldOptions))
+	for value := range oldOptions {
+		removed = append(removed, value)
+	}
+	sort.Strings(removed)
+	return fmt.Sprintf("added %v, removed %v, changed %v", added, removed, changed)
+}
+
+// suppressDisplayOrderPositionDiff ignores an unset display_order of a list
+// element, such as an option, displayed at its position in the list.
+func suppressDisplayOrderPositionDiff(k, old, new string, d *schema.ResourceData) bool {
+	if new != "-1" {
+		return old == new
+	}
+	parts := strings.Split(k, ".")
+	return len(parts) >= 3 && old == parts[len(parts)-2]
+}
+
+// resourcePropertyCustomizeDiff rejects options on properties that are not
+// enumerations and options sharing a value, since HubSpot identifies options
+// by value.
+func resourcePropertyCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	options := d.Get("option").([]interface{})
+	if len(options) == 0 {
+		return nil
+	}
+	if d.NewValueKnown("type") && d.Get("type").(string) != "enumeration" {
+		return fmt.Errorf("option blocks are only allowed on properties of type enumeration")
+	}
+	values := make(map[string]bool, len(options))
+	for i, v := range options {
+		option, ok := v.(map[string]interface{})
+		if !ok || !d.NewValueKnown(fmt.Sprintf("option.%d.value", i)) {
+			continue
+		}
+		value, _ := option["value"].(string)
+		if values[value] {
+			return fmt.Errorf("option value %q is used more than once", value)
+		}
+		values[value] = true
+	}
+	return nil
+}
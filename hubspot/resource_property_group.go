@@ -0,0 +1,131 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-hubspot/client"
+	"time"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourcePropertyGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePropertyGroupCreate,
+		ReadContext:   resourcePropertyGroupRead,
+		UpdateContext: resourcePropertyGroupUpdate,
+		DeleteContext: resourcePropertyGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePropertyGroupImporter,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"object_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"label": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"display_order": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  -1,
+			},
+		},
+	}
+}
+
+func resourcePropertyGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	objectType := d.Get("object_type").(string)
+	group := expandPropertyGroup(d)
+	if err := apiClient.CreatePropertyGroup(ctx, objectType, &group); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(objectTypeId(objectType, group.Name))
+	return resourcePropertyGroupRead(ctx, d, m)
+}
+
+func resourcePropertyGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, name, err := parseObjectTypeId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	group, err := apiClient.GetPropertyGroup(ctx, objectType, name)
+	if err != nil {
+		if client.IsNotFound(err) {
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
+	d.Set("object_type", objectType)
+	d.Set("name", group.Name)
+	d.Set("label", group.Label)
+	d.Set("display_order", group.DisplayOrder)
+	return diags
+}
+
+func resourcePropertyGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	group := expandPropertyGroup(d)
+	if err := apiClient.UpdatePropertyGroup(ctx, d.Get("object_type").(string), &group); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourcePropertyGroupRead(ctx, d, m)
+}
+
+func resourcePropertyGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	apiClient := m.(*client.Client)
+	objectType, name, err := parseObjectTypeId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := apiClient.DeletePropertyGroup(ctx, objectType, name); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func resourcePropertyGroupImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if _, _, err := parseObjectTypeId(id); err != nil {
+		return nil, err
+	}
+	diags := resourcePropertyGroupRead(ctx, d, m)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("property group %s does not exist", id)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandPropertyGroup(d *schema.ResourceData) client.PropertyGroup {
+	return client.PropertyGroup{
+		Name:         d.Get("name").(string),
+		Label:        d.Get("label").(string),
+		DisplayOrder: d.Get("display_order").(int),
+	}
+}
@@ -0,0 +1,57 @@
+package hubspot
+
+import (
+	"fmt"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPropertyGroup_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckPropertyGroupBasic("Preferences", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_property_group.preferences", "id", "contacts/tf_preferences"),
+					resource.TestCheckResourceAttr("hubspot_property_group.preferences", "label", "Preferences"),
+					resource.TestCheckResourceAttr("hubspot_property.channel", "group_name", "tf_preferences"),
+				),
+			},
+			{
+				Config: testAccCheckPropertyGroupBasic("Contact preferences", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_property_group.preferences", "label", "Contact preferences"),
+					resource.TestCheckResourceAttr("hubspot_property_group.preferences", "display_order", "2"),
+				),
+			},
+			{
+				ResourceName:      "hubspot_property_group.preferences",
+				ImportState:       true,
+				ImportStateId:     "contacts/tf_preferences",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckPropertyGroupBasic(label string, displayOrder int) string {
+	return fmt.Sprintf(`
+	resource "hubspot_property_group" "preferences" {
+		object_type   = "contacts"
+		name          = "tf_preferences"
+		label         = "%s"
+		display_order = %d
+	}
+
+	resource "hubspot_property" "channel" {
+		object_type = "contacts"
+		name        = "tf_preferred_channel"
+		label       = "Preferred channel"
+		type        = "string"
+		field_type  = "text"
+		group_name  = hubspot_property_group.preferences.name
+	}
+	`, label, displayOrder)
+}
@@ -0,0 +1,293 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-hubspot/client"
+	"terraform-provider-hubspot/hubspottest"
+	"terraform-provider-hubspot/token"
+	"testing"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccProperty_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckPropertyBasic("Favorite color"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_property.color", "id", "contacts/tf_favorite_color"),
+					resource.TestCheckResourceAttr("hubspot_property.color", "label", "Favorite color"),
+					resource.TestCheckResourceAttr("hubspot_property.color", "option.#", "2"),
+					resource.TestCheckResourceAttr("hubspot_property.color", "option.1.value", "blue"),
+				),
+			},
+			{
+				Config: testAccCheckPropertyBasic("Favourite colour"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_property.color", "label", "Favourite colour"),
+				),
+			},
+			{
+				ResourceName:      "hubspot_property.color",
+				ImportState:       true,
+				ImportStateId:     "contacts/tf_favorite_color",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckPropertyBasic(label string) string {
+	return fmt.Sprintf(`
+	resource "hubspot_property" "color" {
+		object_type = "contacts"
+		name        = "tf_favorite_color"
+		label       = "%s"
+		type        = "enumeration"
+		field_type  = "select"
+		group_name  = "contactinformation"
+		form_field  = true
+
+		option {
+			label = "Red"
+			value = "red"
+		}
+		option {
+			label = "Blue"
+			value = "blue"
+		}
+	}
+	`, label)
+}
+
+func TestParseObjectTypeId(t *testing.T) {
+	objectType, name, err := parseObjectTypeId("contacts/favorite_color")
+	if err != nil || objectType != "contacts" || name != "favorite_color" {
+		t.Fatalf("unexpected %s, %s, %v", objectType, name, err)
+	}
+	for _, id := range []string{"favorite_color", "contacts/", "/favorite_color", "contacts/favorite/color"} {
+		if _, _, err := parseObjectTypeId(id); err == nil {
+			t.Fatalf("expected an error for %q", id)
+		}
+	}
+}
+
+func TestAccProperty_Options(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckPropertyOptions(`
+		option {
+			label = "Red"
+			value = "red"
+		}
+		option {
+			label = "Blue"
+			value = "blue"
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_property.size", "option.0.display_order", "0"),
+					resource.TestCheckResourceAttr("hubspot_property.size", "option.1.display_order", "1"),
+				),
+			},
+			{
+				Config: testAccCheckPropertyOptions(`
+		option {
+			label = "Crimson"
+			value = "red"
+		}
+		option {
+			label  = "Blue"
+			value  = "blue"
+			hidden = true
+		}
+		option {
+			label         = "Green"
+			value         = "green"
+			display_order = 0
+		}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_property.size", "option.#", "3"),
+					resource.TestCheckResourceAttr("hubspot_property.size", "option.0.label", "Crimson"),
+					resource.TestCheckResourceAttr("hubspot_property.size", "option.1.hidden", "true"),
+					resource.TestCheckResourceAttr("hubspot_property.size", "option.2.display_order", "0"),
+				),
+			},
+			{
+				Config: testAccCheckPropertyOptions(`
+		option {
+			label = "Blue"
+			value = "blue"
+		}
+		option {
+			label = "Navy"
+			value = "blue"
+		}`),
+				ExpectError: regexp.MustCompile(`option value "blue" is used more than once`),
+			},
+		},
+	})
+}
+
+func testAccCheckPropertyOptions(options string) string {
+	return fmt.Sprintf(`
+	resource "hubspot_property" "size" {
+		object_type = "contacts"
+		name        = "tf_color_options"
+		label       = "Color"
+		type        = "enumeration"
+		field_type  = "select"
+		group_name  = "contactinformation"
+		%s
+	}
+	`, options)
+}
+
+func TestOrderPropertyOptions(t *testing.T) {
+	options := []client.PropertyOption{
+		{Value: "green", DisplayOrder: 0},
+		{Value: "blue", DisplayOrder: 1},
+		{Value: "purple", DisplayOrder: 3},
+		{Value: "red", DisplayOrder: 2},
+		{Value: "yellow", DisplayOrder: 2},
+	}
+	known := []client.PropertyOption{
+		{Value: "red"},
+		{Value: "blue"},
+		{Value: "black"},
+	}
+	var values []string
+	for _, option := range orderPropertyOptions(options, known) {
+		values = append(values, option.Value)
+	}
+	assert.Equal(t, []string{"red", "blue", "green", "yellow", "purple"}, values)
+}
+
+func TestResourcePropertyUpdate_removeOptions(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	server.AddProperty("contacts", hubspottest.Property{
+		Name:      "favorite_color",
+		Label:     "Favorite color",
+		Type:      "enumeration",
+		FieldType: "select",
+		GroupName: "contactinformation",
+		Options:   []hubspottest.PropertyOption{{Label: "Red", Value: "red"}},
+	})
+	apiClient := client.NewClient(token.StaticSource(hubspottest.AccessToken))
+	apiClient.HostURL = server.URL
+
+	config := map[string]interface{}{
+		"object_type": "contacts",
+		"name":        "favorite_color",
+		"label":       "Favorite color",
+		"type":        "enumeration",
+		"field_type":  "select",
+		"group_name":  "contactinformation",
+		"option": []interface{}{
+			map[string]interface{}{"label": "Red", "value": "red"},
+		},
+	}
+	r := resourceProperty()
+	state := schema.TestResourceDataRaw(t, r.Schema, config)
+	state.SetId("contacts/favorite_color")
+	delete(config, "option")
+	diff, err := r.Diff(context.Background(), state.State(), terraform.NewResourceConfigRaw(config), apiClient)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d, err := schema.InternalMap(r.Schema).Data(state.State(), diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diags := resourcePropertyUpdate(context.Background(), d, apiClient); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	property, _ := server.Property("contacts", "favorite_color")
+	assert.Empty(t, property.Options, "removing every option block must remove the options")
+}
+
+func TestDescribeOptionChanges(t *testing.T) {
+	old := []client.PropertyOption{
+		{Label: "Red", Value: "red"},
+		{Label: "Blue", Value: "blue", DisplayOrder: 1},
+		{Label: "Black", Value: "black", DisplayOrder: 2},
+	}
+	new := []client.PropertyOption{
+		{Label: "Crimson", Value: "red"},
+		{Label: "Blue", Value: "blue", DisplayOrder: 1},
+		{Label: "Green", Value: "green", DisplayOrder: 2},
+	}
+	assert.Equal(t, "added [green], removed [black], changed [red]", describeOptionChanges(old, new))
+}
+
+func TestResourcePropertyCustomizeDiff(t *testing.T) {
+	testCases := []struct {
+		testName  string
+		config    map[string]interface{}
+		expectErr bool
+	}{
+		{
+			testName: "enumeration options",
+			config: map[string]interface{}{
+				"type": "enumeration",
+				"option": []interface{}{
+					map[string]interface{}{"label": "Red", "value": "red"},
+					map[string]interface{}{"label": "Blue", "value": "blue"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			testName: "duplicate values",
+			config: map[string]interface{}{
+				"type": "enumeration",
+				"option": []interface{}{
+					map[string]interface{}{"label": "Red", "value": "red"},
+					map[string]interface{}{"label": "Crimson", "value": "red"},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			testName: "options of a string property",
+			config: map[string]interface{}{
+				"type": "string",
+				"option": []interface{}{
+					map[string]interface{}{"label": "Red", "value": "red"},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			config := map[string]interface{}{
+				"object_type": "contacts",
+				"name":        "favorite_color",
+				"label":       "Favorite color",
+				"field_type":  "select",
+				"group_name":  "contactinformation",
+			}
+			for k, v := range tc.config {
+				config[k] = v
+			}
+			_, err := resourceProperty().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), nil)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,141 +3,312 @@ package hubspot
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strings"
+	"log"
+	"net/mail"
+	"reflect"
 	"terraform-provider-hubspot/client"
 	"time"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// validateEmail accepts a bare RFC 5322 address such as Jane.Doe@Company.COM,
+// without display name or angle brackets.
 func validateEmail(v interface{}, k string) (ws []string, es []error) {
-	var errs []error
-	var warns []string
 	value := v.(string)
-	var emailRegex = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
-	if !(emailRegex.MatchString(value)) {
-		errs = append(errs, fmt.Errorf("Expected EmailId is not valid  %s", k))
-		return warns, errs
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Name != "" || address.Address != value {
+		es = append(es, fmt.Errorf("expected %s to be a valid email address, got %q", k, value))
 	}
 	return
 }
 
+// suppressEmailCaseDiff ignores case differences between the configured email
+// and the lowercased email HubSpot returns.
+func suppressEmailCaseDiff(k, old, new string, d *schema.ResourceData) bool {
+	return client.NormalizeEmail(old) == client.NormalizeEmail(new)
+}
+
 func resourceUser() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceUserCreate,
+		CustomizeDiff: resourceUserCustomizeDiff,
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
 		DeleteContext: resourceUserDelete,
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceUserImporter,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(2 * time.Minute),
+			Read:   schema.DefaultTimeout(2 * time.Minute),
+			Update: schema.DefaultTimeout(2 * time.Minute),
+			Delete: schema.DefaultTimeout(2 * time.Minute),
+		},
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceUserV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceUserStateUpgradeV0,
+			},
+		},
+		Schema: resourceUserSchema(),
+	}
+}
+
+func resourceUserSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"email": &schema.Schema{
+			Type:             schema.TypeString,
+			Required:         true,
+			ValidateFunc:     validateEmail,
+			DiffSuppressFunc: suppressEmailCaseDiff,
+		},
+		"role_id": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"primary_team_id": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"secondary_team_ids": &schema.Schema{
+			Type:     schema.TypeSet,
+			Optional: true,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"first_name": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"last_name": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"send_welcome_email": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"super_admin": &schema.Schema{
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"adopt_existing": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"deletion_policy": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "delete",
+			ValidateFunc: validation.StringInSlice([]string{"delete", "remove_role", "abandon"}, false),
+		},
+		"on_email_change": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "replace",
+			ValidateFunc: validation.StringInSlice([]string{"replace", "error"}, false),
+		},
+	}
+}
+
+// resourceUserCustomizeDiff plans a replacement of users whose email changes,
+// since HubSpot cannot change the email of a user, or rejects the plan when
+// on_email_change is "error".
+func resourceUserCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	old, new := d.GetChange("email")
+	if d.Id() == "" || client.NormalizeEmail(old.(string)) == client.NormalizeEmail(new.(string)) {
+		return nil
+	}
+	if d.Get("on_email_change").(string) == "error" {
+		return fmt.Errorf("email of user %s cannot change from %s to %s: HubSpot does not allow changing the email of a user, set on_email_change = \"replace\" to recreate the user", d.Id(), old, new)
+	}
+	return d.ForceNew("email")
+}
+
+// resourceUserV0 is the schema of version 0 states, whose ID is the user's
+// email rather than the numeric HubSpot user ID. It must not change with
+// resourceUserSchema.
+func resourceUserV0() *schema.Resource {
+	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"email": &schema.Schema{
-				Type:         schema.TypeString,
-				Required:     true,
-				ValidateFunc: validateEmail,
+				Type:     schema.TypeString,
+				Required: true,
 			},
 			"role_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 				Computed: true,
 			},
+			"primary_team_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"secondary_team_ids": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"first_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"last_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"send_welcome_email": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"super_admin": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
 		},
 	}
 }
 
+// resourceUserStateUpgradeV0 replaces the email ID of a version 0 state with
+// the numeric user ID. States of users that no longer exist are kept as they
+// are so that the next refresh removes them.
+func resourceUserStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+	apiClient := m.(*client.Client)
+	userId, _ := rawState["id"].(string)
+	if client.UserIdProperty(userId) == client.IdPropertyUserId {
+		return rawState, nil
+	}
+	user, err := apiClient.GetUser(ctx, userId)
+	if err != nil {
+		if client.IsNotFound(err) {
+			return rawState, nil
+		}
+		return nil, err
+	}
+	rawState["id"] = user.Id
+	return rawState, nil
+}
+
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	var diags diag.Diagnostics
 	apiClient := m.(*client.Client)
 	user := client.User{
-		Email:  d.Get("email").(string),
-		RoleId: d.Get("role_id").(string),
-	}
-	var err error
-	retryErr := resource.Retry(2*time.Minute, func() *resource.RetryError {
-		if err = apiClient.CreateUser(&user); err != nil {
-			if apiClient.IsRetry(err) {
-				return resource.RetryableError(err)
-			}
-			return resource.NonRetryableError(err)
-		}
-		return nil
-	})
-	if retryErr != nil {
-		time.Sleep(2 * time.Second)
-		return diag.FromErr(retryErr)
+		Email:            d.Get("email").(string),
+		RoleId:           d.Get("role_id").(string),
+		PrimaryTeamId:    d.Get("primary_team_id").(string),
+		SecondaryTeamIds: expandStringSet(d.Get("secondary_team_ids").(*schema.Set)),
+		FirstName:        d.Get("first_name").(string),
+		LastName:         d.Get("last_name").(string),
+		SendWelcomeEmail: d.Get("send_welcome_email").(bool),
+	}
+	err := apiClient.CreateUser(ctx, &user)
+	if client.IsConflict(err) && d.Get("adopt_existing").(bool) {
+		return resourceUserAdopt(ctx, d, m)
 	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	d.SetId(user.Email)
-	resourceUserRead(ctx, d, m)
-	return diags
+	d.SetId(user.Id)
+	return resourceUserRead(ctx, d, m)
+}
+
+// resourceUserAdopt takes the existing user with the configured email under
+// management, updating the role, teams and names set in the configuration.
+func resourceUserAdopt(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*client.Client)
+	existing, err := apiClient.GetUser(ctx, d.Get("email").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(existing.Id)
+	user := *existing
+	if v, ok := d.GetOk("role_id"); ok {
+		user.RoleId = v.(string)
+	}
+	if v, ok := d.GetOk("primary_team_id"); ok {
+		user.PrimaryTeamId = v.(string)
+	}
+	if v, ok := d.GetOk("secondary_team_ids"); ok {
+		user.SecondaryTeamIds = expandStringSet(v.(*schema.Set))
+	}
+	if v, ok := d.GetOk("first_name"); ok {
+		user.FirstName = v.(string)
+	}
+	if v, ok := d.GetOk("last_name"); ok {
+		user.LastName = v.(string)
+	}
+	if !reflect.DeepEqual(&user, existing) {
+		if err := apiClient.UpdateUser(ctx, &user); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	detail := fmt.Sprintf("User %s (%s) already existed in HubSpot and is now managed by Terraform.", existing.Email, existing.Id)
+	if user.RoleId != existing.RoleId {
+		detail += fmt.Sprintf(" Its role was changed from %q to %q.", existing.RoleId, user.RoleId)
+	}
+	diags := diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Adopted existing HubSpot user",
+		Detail:   detail,
+	}}
+	return append(diags, resourceUserRead(ctx, d, m)...)
 }
 
 func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	apiClient := m.(*client.Client)
 	userId := d.Id()
-	retryErr := resource.Retry(2*time.Minute, func() *resource.RetryError {
-		user, err := apiClient.GetUser(userId)
-		if err != nil {
-			if apiClient.IsRetry(err) {
-				return resource.RetryableError(err)
-			}
-			return resource.NonRetryableError(err)
-		}
-		d.Set("email", user.Email)
-		d.Set("role_id", user.RoleId)
-		return nil
-	})
-	if retryErr != nil {
-		if strings.Contains(retryErr.Error(), "User Does Not Exist") == true {
+	user, err := apiClient.GetUser(ctx, userId)
+	if err != nil {
+		if client.IsNotFound(err) {
 			d.SetId("")
 			return diags
 		}
-		return diag.FromErr(retryErr)
+		return diag.FromErr(err)
 	}
+	d.Set("email", user.Email)
+	d.Set("role_id", user.RoleId)
+	d.Set("primary_team_id", user.PrimaryTeamId)
+	d.Set("secondary_team_ids", user.SecondaryTeamIds)
+	d.Set("first_name", user.FirstName)
+	d.Set("last_name", user.LastName)
+	d.Set("super_admin", user.SuperAdmin)
 	return diags
 }
 
 func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	apiClient := m.(*client.Client)
-	var diags diag.Diagnostics
-	if d.HasChange("email") {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "User not allowed to change email",
-			Detail:   "User not allowed to change email",
-		})
-
-		return diags
-	}
-	if d.HasChange("role_id") {
+	if d.HasChanges("role_id", "primary_team_id", "secondary_team_ids", "first_name", "last_name") {
 		user := client.User{
-			Email:  d.Get("email").(string),
-			RoleId: d.Get("role_id").(string),
-		}
-		var err error
-		retryErr := resource.Retry(2*time.Minute, func() *resource.RetryError {
-			if err = apiClient.UpdateUser(&user); err != nil {
-				if apiClient.IsRetry(err) {
-					return resource.RetryableError(err)
-				}
-				return resource.NonRetryableError(err)
-			}
-			return nil
-		})
-		if retryErr != nil {
-			time.Sleep(2 * time.Second)
-			return diag.FromErr(retryErr)
+			Id:               d.Id(),
+			Email:            d.Get("email").(string),
+			RoleId:           d.Get("role_id").(string),
+			PrimaryTeamId:    d.Get("primary_team_id").(string),
+			SecondaryTeamIds: expandStringSet(d.Get("secondary_team_ids").(*schema.Set)),
+			FirstName:        d.Get("first_name").(string),
+			LastName:         d.Get("last_name").(string),
 		}
-		if err != nil {
+		if err := apiClient.UpdateUser(ctx, &user); err != nil {
 			return diag.FromErr(err)
 		}
-		return diags
 	}
 	return resourceUserRead(ctx, d, m)
 }
@@ -146,22 +317,24 @@ func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface
 	var diags diag.Diagnostics
 	apiClient := m.(*client.Client)
 	userId := d.Id()
-	var err error
-	retryErr := resource.Retry(2*time.Minute, func() *resource.RetryError {
-		if err = apiClient.DeleteUser(userId); err != nil {
-			if apiClient.IsRetry(err) {
-				return resource.RetryableError(err)
-			}
-			return resource.NonRetryableError(err)
+	switch d.Get("deletion_policy").(string) {
+	case "abandon":
+		log.Printf("[DEBUG] Leaving user %s in HubSpot, deletion_policy is abandon", userId)
+	case "remove_role":
+		user := client.User{
+			Id:            userId,
+			Email:         d.Get("email").(string),
+			PrimaryTeamId: d.Get("primary_team_id").(string),
+			FirstName:     d.Get("first_name").(string),
+			LastName:      d.Get("last_name").(string),
+		}
+		if err := apiClient.UpdateUser(ctx, &user); err != nil {
+			return diag.FromErr(err)
+		}
+	default:
+		if err := apiClient.DeleteUser(ctx, userId); err != nil {
+			return diag.FromErr(err)
 		}
-		return nil
-	})
-	if retryErr != nil {
-		time.Sleep(2 * time.Second)
-		return diag.FromErr(retryErr)
-	}
-	if err != nil {
-		return diag.FromErr(err)
 	}
 	d.SetId("")
 	return diags
@@ -170,11 +343,29 @@ func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface
 func resourceUserImporter(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	apiClient := m.(*client.Client)
 	userId := d.Id()
-	user, err := apiClient.GetUser(userId)
+	user, err := apiClient.GetUser(ctx, userId)
 	if err != nil {
 		return nil, err
 	}
+	d.SetId(user.Id)
 	d.Set("email", user.Email)
 	d.Set("role_id", user.RoleId)
+	d.Set("primary_team_id", user.PrimaryTeamId)
+	d.Set("secondary_team_ids", user.SecondaryTeamIds)
+	d.Set("first_name", user.FirstName)
+	d.Set("last_name", user.LastName)
+	d.Set("send_welcome_email", true)
+	d.Set("on_email_change", "replace")
+	d.Set("adopt_existing", false)
+	d.Set("deletion_policy", "delete")
+	d.Set("super_admin", user.SuperAdmin)
 	return []*schema.ResourceData{d}, nil
 }
+
+func expandStringSet(set *schema.Set) []string {
+	values := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		values = append(values, v.(string))
+	}
+	return values
+}
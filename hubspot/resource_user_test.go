@@ -1,15 +1,28 @@
 package hubspot
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"terraform-provider-hubspot/client"
+	"terraform-provider-hubspot/hubspottest"
+	"terraform-provider-hubspot/token"
 	"testing"
+	"github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccUser_Basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckUserBasic(),
@@ -33,8 +46,8 @@ func testAccCheckUserBasic() string {
 
 func TestAccUser_Update(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckUserUpdatePre(),
@@ -71,3 +84,498 @@ func testAccCheckUserUpdatePost() string {
 	}
 	`)
 }
+
+func TestAccUser_Teams(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUserTeams(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_user.user1", "primary_team_id", "4801"),
+					resource.TestCheckResourceAttr("hubspot_user.user1", "secondary_team_ids.#", "1"),
+					resource.TestCheckTypeSetElemAttr("hubspot_user.user1", "secondary_team_ids.*", "4802"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckUserTeams() string {
+	return fmt.Sprintf(`
+	resource "hubspot_user" "user1" {
+		email              = "saurabh.saini@clevertap.com"
+		role_id            = "76891"
+		primary_team_id    = "4801"
+		secondary_team_ids = ["4802"]
+	}
+	`)
+}
+
+func TestAccUser_Name(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUserName("Saurabh"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_user.user1", "first_name", "Saurabh"),
+					resource.TestCheckResourceAttr("hubspot_user.user1", "last_name", "Saini"),
+					resource.TestCheckResourceAttr("hubspot_user.user1", "send_welcome_email", "false"),
+					resource.TestCheckResourceAttr("hubspot_user.user1", "super_admin", "false"),
+				),
+			},
+			{
+				Config: testAccCheckUserName("Saurav"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_user.user1", "first_name", "Saurav"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckUserName(firstName string) string {
+	return fmt.Sprintf(`
+	resource "hubspot_user" "user1" {
+		email              = "saurabh.saini@clevertap.com"
+		role_id            = "76891"
+		first_name         = "%s"
+		last_name          = "Saini"
+		send_welcome_email = false
+	}
+	`, firstName)
+}
+
+func TestAccUser_Import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUserBasic(),
+			},
+			{
+				ResourceName:      "hubspot_user.user1",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "hubspot_user.user1",
+				ImportState:       true,
+				ImportStateId:     "saurabh.saini@clevertap.com",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceUserStateUpgradeV0(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	server.AddUser(hubspottest.User{Id: "24791265", Email: "thesaurabhsaini@gmail.com"})
+	apiClient := client.NewClient(token.StaticSource(hubspottest.AccessToken))
+	apiClient.HostURL = server.URL
+
+	testCases := []struct {
+		testName   string
+		id         string
+		expectedId string
+	}{
+		{
+			testName:   "email id",
+			id:         "thesaurabhsaini@gmail.com",
+			expectedId: "24791265",
+		},
+		{
+			testName:   "numeric id",
+			id:         "24791265",
+			expectedId: "24791265",
+		},
+		{
+			testName:   "deleted user",
+			id:         "saurabh.saini@clevertap.com",
+			expectedId: "saurabh.saini@clevertap.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			state, err := resourceUserStateUpgradeV0(context.Background(), map[string]interface{}{"id": tc.id}, apiClient)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if state["id"] != tc.expectedId {
+				t.Fatalf("expected id %s, got %v", tc.expectedId, state["id"])
+			}
+		})
+	}
+}
+
+func TestAccUser_EmailChange(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUserEmail("saurabh.saini@clevertap.com", "error"),
+			},
+			{
+				Config:      testAccCheckUserEmail("saurabh@clevertap.com", "error"),
+				ExpectError: regexp.MustCompile("HubSpot does not allow changing the email of a user"),
+			},
+			{
+				Config: testAccCheckUserEmail("saurabh@clevertap.com", "replace"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_user.user1", "email", "saurabh@clevertap.com"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckUserEmail(email, onEmailChange string) string {
+	return fmt.Sprintf(`
+	resource "hubspot_user" "user1" {
+		email           = "%s"
+		role_id         = "76891"
+		on_email_change = "%s"
+	}
+	`, email, onEmailChange)
+}
+
+func TestResourceUserCustomizeDiff(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		email         string
+		onEmailChange string
+		expectErr     bool
+		expectReplace bool
+	}{
+		{
+			testName:      "unchanged email",
+			email:         "saurabh.saini@clevertap.com",
+			onEmailChange: "error",
+			expectErr:     false,
+			expectReplace: false,
+		},
+		{
+			testName:      "email case change",
+			email:         "Saurabh.Saini@CleverTap.com",
+			onEmailChange: "error",
+			expectErr:     false,
+			expectReplace: false,
+		},
+		{
+			testName:      "replace",
+			email:         "saurabh@clevertap.com",
+			onEmailChange: "replace",
+			expectErr:     false,
+			expectReplace: true,
+		},
+		{
+			testName:      "error",
+			email:         "saurabh@clevertap.com",
+			onEmailChange: "error",
+			expectErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			state := &terraform.InstanceState{
+				ID: "24813958",
+				Attributes: map[string]string{
+					"id":                 "24813958",
+					"email":              "saurabh.saini@clevertap.com",
+					"role_id":            "76891",
+					"send_welcome_email": "true",
+					"on_email_change":    tc.onEmailChange,
+				},
+			}
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"email":           tc.email,
+				"role_id":         "76891",
+				"on_email_change": tc.onEmailChange,
+			})
+			diff, err := resourceUser().Diff(context.Background(), state, config, nil)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if replace := diff != nil && diff.RequiresNew(); replace != tc.expectReplace {
+				t.Fatalf("expected replace %t, got %t", tc.expectReplace, replace)
+			}
+		})
+	}
+}
+
+func TestAccUser_MixedCaseEmail(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckUserEmail("Saurabh.Saini@CleverTap.COM", "error"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hubspot_user.user1", "email", "saurabh.saini@clevertap.com"),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateEmail(t *testing.T) {
+	testCases := []struct {
+		email     string
+		expectErr bool
+	}{
+		{email: "saurabh.saini@clevertap.com", expectErr: false},
+		{email: "Jane.Doe@Company.COM", expectErr: false},
+		{email: "jane+hubspot@company.technology", expectErr: false},
+		{email: "jane", expectErr: true},
+		{email: "jane@", expectErr: true},
+		{email: "Jane Doe <jane@company.com>", expectErr: true},
+		{email: " jane@company.com", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.email, func(t *testing.T) {
+			_, errs := validateEmail(tc.email, "email")
+			if tc.expectErr && len(errs) == 0 {
+				t.Fatal("expected an error")
+			}
+			if !tc.expectErr && len(errs) != 0 {
+				t.Fatalf("err: %v", errs)
+			}
+		})
+	}
+}
+
+func TestResourceUserCreate_adoptExisting(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		adoptExisting bool
+		expectErr     bool
+	}{
+		{
+			testName:      "conflict is an error",
+			adoptExisting: false,
+			expectErr:     true,
+		},
+		{
+			testName:      "conflict adopts the user",
+			adoptExisting: true,
+			expectErr:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			server := hubspottest.NewServer()
+			defer server.Close()
+			server.AddUser(hubspottest.User{Id: "24813958", Email: "saurabh.saini@clevertap.com", RoleId: "76894", FirstName: "Saurabh"})
+			apiClient := client.NewClient(token.StaticSource(hubspottest.AccessToken))
+			apiClient.HostURL = server.URL
+
+			d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+				"email":          "Saurabh.Saini@clevertap.com",
+				"role_id":        "76891",
+				"adopt_existing": tc.adoptExisting,
+			})
+			diags := resourceUserCreate(context.Background(), d, apiClient)
+			if tc.expectErr {
+				if !diags.HasError() {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning {
+				t.Fatalf("expected a single warning, got %v", diags)
+			}
+			if d.Id() != "24813958" {
+				t.Fatalf("expected id 24813958, got %s", d.Id())
+			}
+			user, _ := server.User("24813958")
+			if user.RoleId != "76891" || user.FirstName != "Saurabh" {
+				t.Fatalf("unexpected user %+v", user)
+			}
+		})
+	}
+}
+
+func TestResourceUserDelete_deletionPolicy(t *testing.T) {
+	testCases := []struct {
+		deletionPolicy string
+		expectExists   bool
+		expectedRoleId string
+	}{
+		{
+			deletionPolicy: "delete",
+			expectExists:   false,
+		},
+		{
+			deletionPolicy: "remove_role",
+			expectExists:   true,
+			expectedRoleId: "",
+		},
+		{
+			deletionPolicy: "abandon",
+			expectExists:   true,
+			expectedRoleId: "76894",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.deletionPolicy, func(t *testing.T) {
+			server := hubspottest.NewServer()
+			defer server.Close()
+			server.AddUser(hubspottest.User{Id: "24813958", Email: "saurabh.saini@clevertap.com", RoleId: "76894", SecondaryTeamIds: []string{"4801"}})
+			apiClient := client.NewClient(token.StaticSource(hubspottest.AccessToken))
+			apiClient.HostURL = server.URL
+
+			d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+				"email":           "saurabh.saini@clevertap.com",
+				"role_id":         "76894",
+				"deletion_policy": tc.deletionPolicy,
+			})
+			d.SetId("24813958")
+			if diags := resourceUserDelete(context.Background(), d, apiClient); diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+			if d.Id() != "" {
+				t.Fatal("expected the user to be removed from state")
+			}
+			user, exists := server.User("24813958")
+			if exists != tc.expectExists {
+				t.Fatalf("expected exists %t, got %t", tc.expectExists, exists)
+			}
+			if exists && user.RoleId != tc.expectedRoleId {
+				t.Fatalf("expected role %q, got %q", tc.expectedRoleId, user.RoleId)
+			}
+		})
+	}
+}
+
+// TestResourceUser_CRUD replays testdata/cassettes/TestResourceUser_CRUD.json,
+// checking the requests sent to create, update and delete a user.
+func TestResourceUser_CRUD(t *testing.T) {
+	apiClient := testAccClient(t)
+	ctx := context.Background()
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+		"email":              "saurabh.saini@clevertap.com",
+		"role_id":            "76891",
+		"first_name":         "Saurabh",
+		"send_welcome_email": false,
+	})
+	if diags := resourceUserCreate(ctx, d, apiClient); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() == "" || d.Get("email") != "saurabh.saini@clevertap.com" || d.Get("role_id") != "76891" || d.Get("first_name") != "Saurabh" {
+		t.Fatalf("unexpected user %s: %v", d.Id(), d.State().Attributes)
+	}
+
+	userId := d.Id()
+	r := resourceUser()
+	state := d.State()
+	diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"email":              "saurabh.saini@clevertap.com",
+		"role_id":            "76894",
+		"first_name":         "Saurabh",
+		"last_name":          "Saini",
+		"send_welcome_email": false,
+	}), apiClient)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d, err = schema.InternalMap(r.Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diags := resourceUserUpdate(ctx, d, apiClient); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != userId || d.Get("role_id") != "76894" || d.Get("last_name") != "Saini" {
+		t.Fatalf("unexpected user %s: %v", d.Id(), d.State().Attributes)
+	}
+
+	if diags := resourceUserDelete(ctx, d, apiClient); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.SetId(userId)
+	if diags := resourceUserRead(ctx, d, apiClient); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected the deleted user to be removed from state")
+	}
+}
+
+func TestResourceUserStateUpgradeV0_rawState(t *testing.T) {
+	server := hubspottest.NewServer()
+	defer server.Close()
+	server.AddUser(hubspottest.User{Id: "24791265", Email: "thesaurabhsaini@gmail.com", RoleId: "76894"})
+	p := server.Provider(Provider)
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(nil)); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	// A version 0 state has the attributes of resourceUserV0 only, and the
+	// email as its ID.
+	rawState := []byte(`{
+		"id": "thesaurabhsaini@gmail.com",
+		"email": "thesaurabhsaini@gmail.com",
+		"role_id": "76894",
+		"primary_team_id": "",
+		"secondary_team_ids": [],
+		"first_name": "",
+		"last_name": "",
+		"send_welcome_email": true,
+		"super_admin": false
+	}`)
+	v0Type := resourceUserV0().CoreConfigSchema().ImpliedType()
+	var v0Attributes []string
+	for name := range v0Type.AttributeTypes() {
+		v0Attributes = append(v0Attributes, name)
+	}
+	sort.Strings(v0Attributes)
+	expectedAttributes := []string{"email", "first_name", "id", "last_name", "primary_team_id", "role_id", "secondary_team_ids", "send_welcome_email", "super_admin"}
+	if !reflect.DeepEqual(v0Attributes, expectedAttributes) {
+		t.Fatalf("version 0 schema changed: expected %v, got %v", expectedAttributes, v0Attributes)
+	}
+	if _, err := json.Unmarshal(rawState, v0Type); err != nil {
+		t.Fatalf("raw state does not match the version 0 schema: %s", err)
+	}
+	response, err := schema.NewGRPCProviderServer(p).UpgradeResourceState(context.Background(), &tfprotov5.UpgradeResourceStateRequest{
+		TypeName: "hubspot_user",
+		Version:  0,
+		RawState: &tfprotov5.RawState{JSON: rawState},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(response.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics %v", response.Diagnostics[0])
+	}
+	state, err := msgpack.Unmarshal(response.UpgradedState.MsgPack, resourceUser().CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if id := state.GetAttr("id").AsString(); id != "24791265" {
+		t.Fatalf("expected id 24791265, got %s", id)
+	}
+	if email := state.GetAttr("email").AsString(); email != "thesaurabhsaini@gmail.com" {
+		t.Fatalf("expected email thesaurabhsaini@gmail.com, got %s", email)
+	}
+	if !state.GetAttr("deletion_policy").IsNull() {
+		t.Fatalf("expected no deletion_policy, got %#v", state.GetAttr("deletion_policy"))
+	}
+}
@@ -0,0 +1,42 @@
+package hubspottest
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ProviderFactories returns provider factories for resource.TestCase whose
+// providers talk to the fake server with OAuth credentials it accepts,
+// regardless of any HUBSPOT_* environment variables.
+func (s *Server) ProviderFactories(newProvider func() *schema.Provider) map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"hubspot": func() (*schema.Provider, error) {
+			return s.Provider(newProvider), nil
+		},
+	}
+}
+
+// Provider returns a provider built by newProvider whose defaults point at the
+// fake server.
+func (s *Server) Provider(newProvider func() *schema.Provider) *schema.Provider {
+	p := newProvider()
+	defaults := map[string]interface{}{
+		"client_id":       "hubspottest-client-id",
+		"client_secret":   "hubspottest-client-secret",
+		"refresh_token":   RefreshToken,
+		"access_token":    nil,
+		"api_key":         nil,
+		"base_url":        s.URL,
+		"oauth_token_url": s.URL + "/oauth/v1/token",
+		"http_proxy":      nil,
+		"ca_bundle_file":  nil,
+	}
+	for name, value := range defaults {
+		if attr, ok := p.Schema[name]; ok {
+			value := value
+			attr.DefaultFunc = func() (interface{}, error) {
+				return value, nil
+			}
+		}
+	}
+	return p
+}
@@ -0,0 +1,257 @@
+package hubspottest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// ModeReplay answers requests from a cassette without touching the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests and writes the scrubbed interactions to
+	// the cassette when the recorder is stopped.
+	ModeRecord
+)
+
+// Cassette is the fixture file format of a Recorder.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording interactions to, or replaying
+// them from, a cassette file. Credentials are never written to a cassette
+// and email addresses are replaced by stable placeholders; on replay the
+// placeholders are mapped back to the addresses used in the requests.
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	emails   map[string]string
+}
+
+// NewRecorder returns a recorder for the cassette at path. In ModeReplay the
+// cassette must exist; the returned error then satisfies os.IsNotExist when
+// nothing was recorded yet. transport forwards the recorded requests in
+// ModeRecord; a nil transport uses http.DefaultTransport.
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		mode:      mode,
+		path:      path,
+		transport: transport,
+		emails:    make(map[string]string),
+	}
+	if mode == ModeRecord {
+		return r, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	return r.roundTrip(request, r.transport)
+}
+
+// Wrap returns a RoundTripper sharing the recorder's cassette that forwards
+// recorded requests through transport, such as the proxy and CA aware
+// transport of a configured client, instead of the recorder's own.
+func (r *Recorder) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		return r.roundTrip(request, transport)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func (r *Recorder) roundTrip(request *http.Request, transport http.RoundTripper) (*http.Response, error) {
+	body, err := readBody(request)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recorded := RecordedRequest{
+		Method: request.Method,
+		URL:    r.scrub(scrubURL(request.URL)),
+		Body:   r.scrub(scrubBody(body)),
+	}
+	if r.mode == ModeRecord {
+		return r.record(request, recorded, transport)
+	}
+	return r.replay(request, recorded)
+}
+
+func (r *Recorder) record(request *http.Request, recorded RecordedRequest, transport http.RoundTripper) (*http.Response, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	header := http.Header{}
+	for _, name := range []string{"Content-Type", "Retry-After", "X-HubSpot-RateLimit-Remaining", "X-HubSpot-RateLimit-Interval-Milliseconds"} {
+		if value := response.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     header,
+			Body:       r.scrub(scrubBody(string(body))),
+		},
+	})
+	return response, nil
+}
+
+// replay answers with the first unused interaction recorded for the same
+// method, URL and body.
+func (r *Recorder) replay(request *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request != recorded {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(r.unscrub(interaction.Response.Body))),
+			ContentLength: -1,
+			Request:       request,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette %s has no unused interaction for %s %s", r.path, recorded.Method, recorded.URL)
+}
+
+// Stop writes the cassette in ModeRecord. It is a no-op in ModeReplay.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func readBody(request *http.Request) (string, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return "", nil
+	}
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+(?:@|%40)[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	secretPattern = regexp.MustCompile(`("(?:access_token|refresh_token|client_secret)"\s*:\s*")[^"]*(")`)
+	formPattern   = regexp.MustCompile(`((?:^|&)(?:refresh_token|client_secret|client_id|hapikey)=)[^&]*`)
+)
+
+const redacted = "REDACTED"
+
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	query := scrubbed.Query()
+	if query.Get("hapikey") != "" {
+		query.Set("hapikey", redacted)
+		scrubbed.RawQuery = query.Encode()
+	}
+	return scrubbed.RequestURI()
+}
+
+func scrubBody(body string) string {
+	body = secretPattern.ReplaceAllString(body, "${1}"+redacted+"${2}")
+	return formPattern.ReplaceAllString(body, "${1}"+redacted)
+}
+
+// scrub replaces email addresses with placeholders derived from their hash,
+// remembering the mapping so replayed responses can be unscrubbed.
+func (r *Recorder) scrub(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, func(match string) string {
+		email := strings.ToLower(strings.Replace(match, "%40", "@", 1))
+		if strings.HasPrefix(email, "redacted-") && strings.HasSuffix(email, "@example.com") {
+			return match
+		}
+		sum := sha256.Sum256([]byte(email))
+		placeholder := fmt.Sprintf("redacted-%x@example.com", sum[:6])
+		r.emails[placeholder] = email
+		if strings.Contains(match, "%40") {
+			return strings.Replace(placeholder, "@", "%40", 1)
+		}
+		return placeholder
+	})
+}
+
+func (r *Recorder) unscrub(s string) string {
+	for placeholder, email := range r.emails {
+		s = strings.ReplaceAll(s, placeholder, email)
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package hubspottest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"terraform-provider-hubspot/client"
+	"terraform-provider-hubspot/hubspottest"
+	"terraform-provider-hubspot/token"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	server := hubspottest.NewServer()
+	server.AddRole(hubspottest.Role{Id: "76891", Name: "Sales"})
+
+	recorder, err := hubspottest.NewRecorder(path, hubspottest.ModeRecord, http.DefaultTransport)
+	assert.NoError(t, err)
+	c := client.NewClient(token.StaticSource(hubspottest.AccessToken))
+	c.HostURL = server.URL
+	c.HTTPClient = &http.Client{Transport: recorder}
+	recorded := exercise(t, c)
+	assert.NoError(t, recorder.Stop())
+	server.Close()
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "ravikishandaiya@gmail.com"), "cassette leaks an email address")
+	assert.False(t, strings.Contains(string(data), hubspottest.AccessToken), "cassette leaks the access token")
+
+	recorder, err = hubspottest.NewRecorder(path, hubspottest.ModeReplay, nil)
+	assert.NoError(t, err)
+	c.HTTPClient = &http.Client{Transport: recorder}
+	replayed := exercise(t, c)
+	assert.Equal(t, recorded, replayed)
+
+	_, err = c.GetRoles(context.Background())
+	assert.Error(t, err, "interactions are only replayed once")
+}
+
+func TestRecorder_Wrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	server := hubspottest.NewServer()
+	defer server.Close()
+	server.AddRole(hubspottest.Role{Id: "76891", Name: "Sales"})
+
+	recorder, err := hubspottest.NewRecorder(path, hubspottest.ModeRecord, nil)
+	assert.NoError(t, err)
+	forwarded := 0
+	transport := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		forwarded++
+		return http.DefaultTransport.RoundTrip(request)
+	})
+	c := client.NewClient(token.StaticSource(hubspottest.AccessToken))
+	c.HostURL = server.URL
+	c.HTTPClient = &http.Client{Transport: recorder.Wrap(transport)}
+	exercise(t, c)
+	assert.NoError(t, recorder.Stop())
+	assert.Equal(t, 4, forwarded, "requests are forwarded through the wrapped transport")
+
+	recorder, err = hubspottest.NewRecorder(path, hubspottest.ModeReplay, nil)
+	assert.NoError(t, err)
+	c.HTTPClient = &http.Client{Transport: recorder.Wrap(transport)}
+	exercise(t, c)
+	assert.Equal(t, 4, forwarded, "replayed requests are not forwarded")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestRecorder_missingCassette(t *testing.T) {
+	_, err := hubspottest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), hubspottest.ModeReplay, nil)
+	assert.Error(t, err)
+}
+
+func exercise(t *testing.T, c *client.Client) *client.User {
+	ctx := context.Background()
+	roles, err := c.GetRoles(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, c.CreateUser(ctx, &client.User{Email: "ravikishandaiya@gmail.com", RoleId: roles[0].Id}))
+	user, err := c.GetUser(ctx, "ravikishandaiya@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "ravikishandaiya@gmail.com", user.Email)
+	assert.NoError(t, c.DeleteUser(ctx, "ravikishandaiya@gmail.com"))
+	return user
+}
@@ -0,0 +1,1001 @@
+// Package hubspottest provides an in-memory fake of the HubSpot API for
+// offline client unit tests and provider acceptance tests.
+package hubspottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	AccessToken  = "hubspottest-access-token"
+	RefreshToken = "hubspottest-refresh-token"
+)
+
+type User struct {
+	Id               string   `json:"id"`
+	Email            string   `json:"email"`
+	RoleId           string   `json:"roleId,omitempty"`
+	PrimaryTeamId    string   `json:"primaryTeamId,omitempty"`
+	SecondaryTeamIds []string `json:"secondaryTeamIds,omitempty"`
+	FirstName        string   `json:"firstName,omitempty"`
+	LastName         string   `json:"lastName,omitempty"`
+	SuperAdmin       bool     `json:"superAdmin"`
+}
+
+type Role struct {
+	Id                   string `json:"id"`
+	Name                 string `json:"name"`
+	RequiresBillingWrite bool   `json:"requiresBillingWrite"`
+}
+
+type Team struct {
+	Id               string   `json:"id"`
+	Name             string   `json:"name"`
+	UserIds          []string `json:"userIds"`
+	SecondaryUserIds []string `json:"secondaryUserIds"`
+}
+
+type PropertyOption struct {
+	Label        string `json:"label"`
+	Value        string `json:"value"`
+	Description  string `json:"description,omitempty"`
+	DisplayOrder int    `json:"displayOrder"`
+	Hidden       bool   `json:"hidden"`
+}
+
+type Property struct {
+	Name               string           `json:"name"`
+	Label              string           `json:"label"`
+	Type               string           `json:"type"`
+	FieldType          string           `json:"fieldType"`
+	GroupName          string           `json:"groupName"`
+	Description        string           `json:"description,omitempty"`
+	Options            []PropertyOption `json:"options"`
+	CalculationFormula string           `json:"calculationFormula,omitempty"`
+	Hidden             bool             `json:"hidden"`
+	FormField          bool             `json:"formField"`
+}
+
+type PropertyGroup struct {
+	Name         string `json:"name"`
+	Label        string `json:"label"`
+	DisplayOrder int    `json:"displayOrder"`
+}
+
+type PipelineStage struct {
+	Id           string            `json:"id"`
+	Label        string            `json:"label"`
+	DisplayOrder int               `json:"displayOrder"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+type Pipeline struct {
+	Id           string          `json:"id"`
+	Label        string          `json:"label"`
+	DisplayOrder int             `json:"displayOrder"`
+	Stages       []PipelineStage `json:"stages"`
+}
+
+type ObjectSchemaLabels struct {
+	Singular string `json:"singular"`
+	Plural   string `json:"plural"`
+}
+
+type ObjectSchemaAssociation struct {
+	Id               string `json:"id"`
+	FromObjectTypeId string `json:"fromObjectTypeId"`
+	ToObjectTypeId   string `json:"toObjectTypeId"`
+	Name             string `json:"name,omitempty"`
+}
+
+// ObjectSchema is a custom object. Its properties are kept with the
+// properties of other object types, under ObjectTypeId.
+type ObjectSchema struct {
+	Id                         string                    `json:"id"`
+	ObjectTypeId               string                    `json:"objectTypeId"`
+	FullyQualifiedName         string                    `json:"fullyQualifiedName"`
+	Name                       string                    `json:"name"`
+	Labels                     ObjectSchemaLabels        `json:"labels"`
+	PrimaryDisplayProperty     string                    `json:"primaryDisplayProperty"`
+	RequiredProperties         []string                  `json:"requiredProperties"`
+	SearchableProperties       []string                  `json:"searchableProperties"`
+	SecondaryDisplayProperties []string                  `json:"secondaryDisplayProperties"`
+	Properties                 []Property                `json:"properties"`
+	Associations               []ObjectSchemaAssociation `json:"associations"`
+}
+
+// Failure is an error response injected with FailNext.
+type Failure struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// Server is an httptest.Server emulating the HubSpot endpoints used by the
+// provider. It accepts the OAuth refresh token RefreshToken and the bearer
+// token AccessToken.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	users      map[string]*User
+	roles      []Role
+	teams      []Team
+	properties map[string]map[string]*Property
+	groups     map[string]map[string]*PropertyGroup
+	pipelines  map[string][]*Pipeline
+	records    map[string]int
+	schemas    map[string]*ObjectSchema
+	nextId     int
+	failures   []Failure
+	requests   []string
+}
+
+func NewServer() *Server {
+	s := &Server{
+		users:      make(map[string]*User),
+		properties: make(map[string]map[string]*Property),
+		groups:     make(map[string]map[string]*PropertyGroup),
+		pipelines:  make(map[string][]*Pipeline),
+		records:    make(map[string]int),
+		schemas:    make(map[string]*ObjectSchema),
+		nextId:     1000,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// AddUser seeds a user. A user id is assigned when Id is empty.
+func (s *Server) AddUser(user User) User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user.Id == "" {
+		user.Id = s.newId()
+	}
+	s.users[user.Id] = &user
+	return user
+}
+
+// User returns the user with the given id or email.
+func (s *Server) User(idOrEmail string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.findUser(idOrEmail, "")
+	if user == nil {
+		return User{}, false
+	}
+	return *user, true
+}
+
+func (s *Server) AddRole(role Role) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roles = append(s.roles, role)
+}
+
+func (s *Server) AddTeam(team Team) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams = append(s.teams, team)
+}
+
+// AddProperty seeds a property of the given object type.
+func (s *Server) AddProperty(objectType string, property Property) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objectProperties(objectType)[property.Name] = &property
+}
+
+// Property returns the property of the given object type and name.
+func (s *Server) Property(objectType, name string) (Property, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	property, ok := s.objectProperties(objectType)[name]
+	if !ok {
+		return Property{}, false
+	}
+	return *property, true
+}
+
+// AddPropertyGroup seeds a property group of the given object type.
+func (s *Server) AddPropertyGroup(objectType string, group PropertyGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objectGroups(objectType)[group.Name] = &group
+}
+
+// PropertyGroup returns the property group of the given object type and name.
+func (s *Server) PropertyGroup(objectType, name string) (PropertyGroup, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group, ok := s.objectGroups(objectType)[name]
+	if !ok {
+		return PropertyGroup{}, false
+	}
+	return *group, true
+}
+
+// AddPipeline seeds a pipeline of the given object type. Ids are assigned to
+// the pipeline and its stages when empty.
+func (s *Server) AddPipeline(objectType string, pipeline Pipeline) Pipeline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pipeline.Id == "" {
+		pipeline.Id = s.newId()
+	}
+	pipeline.Stages = append([]PipelineStage(nil), pipeline.Stages...)
+	for i := range pipeline.Stages {
+		if pipeline.Stages[i].Id == "" {
+			pipeline.Stages[i].Id = s.newId()
+		}
+	}
+	s.pipelines[objectType] = append(s.pipelines[objectType], &pipeline)
+	return pipeline
+}
+
+// Pipeline returns the pipeline of the given object type and id.
+func (s *Server) Pipeline(objectType, id string) (Pipeline, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pipeline := s.findPipeline(objectType, id)
+	if pipeline == nil {
+		return Pipeline{}, false
+	}
+	copied := *pipeline
+	copied.Stages = append([]PipelineStage(nil), pipeline.Stages...)
+	return copied, true
+}
+
+// SetStageRecords sets how many records are in a pipeline stage. Like
+// HubSpot, the server refuses to delete stages, or pipelines with stages,
+// that have records.
+func (s *Server) SetStageRecords(stageId string, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[stageId] = count
+}
+
+// AddObjectSchema seeds a custom object with its properties. The id, object
+// type id and fully qualified name are assigned when empty.
+func (s *Server) AddObjectSchema(objectSchema ObjectSchema) ObjectSchema {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addObjectSchema(&objectSchema)
+	return s.objectSchemaResponse(&objectSchema)
+}
+
+// ObjectSchema returns the custom object with the given object type id or
+// fully qualified name.
+func (s *Server) ObjectSchema(objectType string) (ObjectSchema, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objectSchema := s.findObjectSchema(objectType)
+	if objectSchema == nil {
+		return ObjectSchema{}, false
+	}
+	return s.objectSchemaResponse(objectSchema), true
+}
+
+// FailNext makes the next request matching the method and path prefix fail
+// with the given status code. Failures are consumed in the order they were
+// queued, so queuing the same failure several times fails several requests.
+func (s *Server) FailNext(failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure)
+}
+
+// Requests returns "METHOD /path" for every request served so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) newId() string {
+	s.nextId++
+	return strconv.Itoa(s.nextId)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	for i, failure := range s.failures {
+		if failure.Method == r.Method && strings.HasPrefix(r.URL.Path, failure.Path) {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			for k, v := range failure.Header {
+				w.Header()[k] = v
+			}
+			if failure.Body == "" {
+				writeError(w, failure.StatusCode, "INJECTED_FAILURE", http.StatusText(failure.StatusCode))
+				return
+			}
+			w.WriteHeader(failure.StatusCode)
+			w.Write([]byte(failure.Body))
+			return
+		}
+	}
+
+	if r.URL.Path == "/oauth/v1/token" {
+		s.handleToken(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+AccessToken {
+		writeError(w, http.StatusUnauthorized, "INVALID_AUTHENTICATION", "Authentication credentials not found.")
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/settings/v3/users/roles" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": s.roles})
+	case path == "/settings/v3/users/teams" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": s.teams})
+	case path == "/settings/v3/users" && r.Method == http.MethodGet:
+		s.listUsers(w, r)
+	case path == "/settings/v3/users" && r.Method == http.MethodPost:
+		s.createUser(w, r)
+	case strings.HasPrefix(path, "/settings/v3/users/"):
+		s.handleUser(w, r, strings.TrimPrefix(path, "/settings/v3/users/"))
+	case path == "/crm/v3/schemas" || strings.HasPrefix(path, "/crm/v3/schemas/"):
+		s.handleObjectSchemas(w, r, strings.Split(strings.TrimPrefix(path, "/crm/v3/schemas"), "/")[1:])
+	case strings.HasPrefix(path, "/crm/v3/pipelines/"):
+		s.handlePipelines(w, r, strings.Split(strings.TrimPrefix(path, "/crm/v3/pipelines/"), "/"))
+	case strings.HasPrefix(path, "/crm/v3/properties/"):
+		s.handleProperties(w, r, strings.Split(strings.TrimPrefix(path, "/crm/v3/properties/"), "/"))
+	default:
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("%s %s is not emulated", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "refresh_token" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"status": "BAD_GRANT_TYPE", "message": "unsupported grant type"})
+		return
+	}
+	if r.PostForm.Get("refresh_token") != RefreshToken {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"status": "BAD_REFRESH_TOKEN", "message": "missing or unknown refresh token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"refresh_token": RefreshToken,
+		"access_token":  AccessToken,
+		"expires_in":    1800,
+	})
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	users := s.sortedUsers()
+	results := make([]User, 0, len(users))
+	for _, user := range users {
+		results = append(results, *user)
+	}
+	writeJSON(w, http.StatusOK, page(results, r))
+}
+
+// page returns the slice of results selected by the limit and after query
+// parameters in HubSpot's paged response format. The after cursor is the
+// offset of the first result of the next page.
+func page(results interface{}, r *http.Request) map[string]interface{} {
+	items := reflect.ValueOf(results)
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("after"))
+	if offset > items.Len() {
+		offset = items.Len()
+	}
+	end := offset + limit
+	if end > items.Len() {
+		end = items.Len()
+	}
+	body := map[string]interface{}{"results": items.Slice(offset, end).Interface()}
+	if end < items.Len() {
+		body["paging"] = map[string]interface{}{
+			"next": map[string]string{"after": strconv.Itoa(end)},
+		}
+	}
+	return body
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		User
+		SendWelcomeEmail bool `json:"sendWelcomeEmail"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Email == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input JSON")
+		return
+	}
+	if s.findUser(request.Email, "EMAIL") != nil {
+		writeError(w, http.StatusConflict, "CONFLICT", fmt.Sprintf("A user with email %s already exists", request.Email))
+		return
+	}
+	user := request.User
+	user.Id = s.newId()
+	// HubSpot stores emails lowercased.
+	user.Email = strings.ToLower(user.Email)
+	user.SuperAdmin = false
+	s.users[user.Id] = &user
+	writeJSON(w, http.StatusCreated, user)
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request, id string) {
+	user := s.findUser(id, r.URL.Query().Get("idProperty"))
+	if user == nil {
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("User %s not found", id))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, user)
+	case http.MethodPut:
+		var update User
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input JSON")
+			return
+		}
+		user.RoleId = update.RoleId
+		if update.PrimaryTeamId != "" {
+			user.PrimaryTeamId = update.PrimaryTeamId
+		}
+		user.SecondaryTeamIds = update.SecondaryTeamIds
+		if update.FirstName != "" {
+			user.FirstName = update.FirstName
+		}
+		if update.LastName != "" {
+			user.LastName = update.LastName
+		}
+		writeJSON(w, http.StatusOK, user)
+	case http.MethodDelete:
+		delete(s.users, user.Id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "VALIDATION_ERROR", "Method not allowed")
+	}
+}
+
+// findUser looks a user up by id or, when idProperty is EMAIL, by email.
+// An empty idProperty matches either.
+func (s *Server) findUser(id, idProperty string) *User {
+	if idProperty != "EMAIL" {
+		if user, ok := s.users[id]; ok {
+			return user
+		}
+	}
+	if idProperty == "" || idProperty == "EMAIL" {
+		for _, user := range s.sortedUsers() {
+			if strings.EqualFold(user.Email, id) {
+				return user
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) objectProperties(objectType string) map[string]*Property {
+	properties, ok := s.properties[objectType]
+	if !ok {
+		properties = make(map[string]*Property)
+		s.properties[objectType] = properties
+	}
+	return properties
+}
+
+// handleProperties serves /crm/v3/properties/{objectType} and
+// /crm/v3/properties/{objectType}/{name}.
+func (s *Server) handleProperties(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) > 1 && segments[1] == "groups" {
+		s.handlePropertyGroups(w, r, segments[0], segments[2:])
+		return
+	}
+	properties := s.objectProperties(segments[0])
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		results := make([]Property, 0, len(properties))
+		for _, property := range properties {
+			results = append(results, *property)
+		}
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Name < results[j].Name
+		})
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		var property Property
+		if err := json.NewDecoder(r.Body).Decode(&property); err != nil || property.Name == "" || property.Label == "" || property.Type == "" || property.FieldType == "" || property.GroupName == "" {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Property name, label, type, fieldType and groupName are required")
+			return
+		}
+		if _, ok := properties[property.Name]; ok {
+			writeError(w, http.StatusConflict, "OBJECT_ALREADY_EXISTS", fmt.Sprintf("A property named '%s' already exists", property.Name))
+			return
+		}
+		if property.Options == nil {
+			property.Options = []PropertyOption{}
+		}
+		properties[property.Name] = &property
+		writeJSON(w, http.StatusCreated, property)
+	case len(segments) == 2:
+		property, ok := properties[segments[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("Property %s does not exist", segments[1]))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, property)
+		case http.MethodPatch:
+			// Fields missing from the body keep their value.
+			update := *property
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input JSON")
+				return
+			}
+			update.Name = property.Name
+			*property = update
+			writeJSON(w, http.StatusOK, property)
+		case http.MethodDelete:
+			delete(properties, property.Name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "VALIDATION_ERROR", "Method not allowed")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("%s %s is not emulated", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) objectGroups(objectType string) map[string]*PropertyGroup {
+	groups, ok := s.groups[objectType]
+	if !ok {
+		groups = make(map[string]*PropertyGroup)
+		s.groups[objectType] = groups
+	}
+	return groups
+}
+
+// handlePropertyGroups serves /crm/v3/properties/{objectType}/groups and
+// /crm/v3/properties/{objectType}/groups/{name}.
+func (s *Server) handlePropertyGroups(w http.ResponseWriter, r *http.Request, objectType string, segments []string) {
+	groups := s.objectGroups(objectType)
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		results := make([]PropertyGroup, 0, len(groups))
+		for _, group := range groups {
+			results = append(results, *group)
+		}
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Name < results[j].Name
+		})
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		group := PropertyGroup{DisplayOrder: -1}
+		if err := json.NewDecoder(r.Body).Decode(&group); err != nil || group.Name == "" || group.Label == "" {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Property group name and label are required")
+			return
+		}
+		if _, ok := groups[group.Name]; ok {
+			writeError(w, http.StatusConflict, "OBJECT_ALREADY_EXISTS", fmt.Sprintf("A property group named '%s' already exists", group.Name))
+			return
+		}
+		groups[group.Name] = &group
+		writeJSON(w, http.StatusCreated, group)
+	case len(segments) == 1:
+		group, ok := groups[segments[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("Property group %s does not exist", segments[0]))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, group)
+		case http.MethodPatch:
+			update := *group
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input JSON")
+				return
+			}
+			update.Name = group.Name
+			*group = update
+			writeJSON(w, http.StatusOK, group)
+		case http.MethodDelete:
+			delete(groups, group.Name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "VALIDATION_ERROR", "Method not allowed")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("%s %s is not emulated", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) findPipeline(objectType, id string) *Pipeline {
+	for _, pipeline := range s.pipelines[objectType] {
+		if pipeline.Id == id {
+			return pipeline
+		}
+	}
+	return nil
+}
+
+// handlePipelines serves /crm/v3/pipelines/{objectType},
+// /crm/v3/pipelines/{objectType}/{pipelineId} and the stages of a pipeline
+// below /crm/v3/pipelines/{objectType}/{pipelineId}/stages.
+func (s *Server) handlePipelines(w http.ResponseWriter, r *http.Request, segments []string) {
+	objectType := segments[0]
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			results := make([]Pipeline, 0, len(s.pipelines[objectType]))
+			for _, pipeline := range s.pipelines[objectType] {
+				results = append(results, *pipeline)
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+		case http.MethodPost:
+			var pipeline Pipeline
+			if err := json.NewDecoder(r.Body).Decode(&pipeline); err != nil || pipeline.Label == "" || len(pipeline.Stages) == 0 {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Pipeline label and at least one stage are required")
+				return
+			}
+			for i := range pipeline.Stages {
+				if message := validateStage(objectType, pipeline.Stages[i]); message != "" {
+					writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", message)
+					return
+				}
+				pipeline.Stages[i].Id = s.newId()
+			}
+			pipeline.Id = s.newId()
+			s.pipelines[objectType] = append(s.pipelines[objectType], &pipeline)
+			writeJSON(w, http.StatusCreated, pipeline)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "VALIDATION_ERROR", "Method not allowed")
+		}
+		return
+	}
+
+	pipeline := s.findPipeline(objectType, segments[1])
+	if pipeline == nil {
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("Pipeline %s does not exist", segments[1]))
+		return
+	}
+	switch {
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, pipeline)
+	case len(segments) == 2 && r.Method == http.MethodPatch:
+		update := struct {
+			Label        *string `json:"label"`
+			DisplayOrder *int    `json:"displayOrder"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input JSON")
+			return
+		}
+		if update.Label != nil {
+			pipeline.Label = *update.Label
+		}
+		if update.DisplayOrder != nil {
+			pipeline.DisplayOrder = *update.DisplayOrder
+		}
+		writeJSON(w, http.StatusOK, pipeline)
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		for _, stage := range pipeline.Stages {
+			if s.records[stage.Id] > 0 {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", fmt.Sprintf("Pipeline %s has records in stage %s", pipeline.Id, stage.Id))
+				return
+			}
+		}
+		pipelines := s.pipelines[objectType]
+		for i := range pipelines {
+			if pipelines[i] == pipeline {
+				s.pipelines[objectType] = append(pipelines[:i], pipelines[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 3 && segments[2] == "stages" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": pipeline.Stages})
+	case len(segments) == 3 && segments[2] == "stages" && r.Method == http.MethodPost:
+		var stage PipelineStage
+		if err := json.NewDecoder(r.Body).Decode(&stage); err != nil || stage.Label == "" {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Stage label is required")
+			return
+		}
+		if message := validateStage(objectType, stage); message != "" {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", message)
+			return
+		}
+		stage.Id = s.newId()
+		pipeline.Stages = append(pipeline.Stages, stage)
+		writeJSON(w, http.StatusCreated, stage)
+	case len(segments) == 4 && segments[2] == "stages":
+		s.handlePipelineStage(w, r, objectType, pipeline, segments[3])
+	default:
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("%s %s is not emulated", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) handlePipelineStage(w http.ResponseWriter, r *http.Request, objectType string, pipeline *Pipeline, stageId string) {
+	index := -1
+	for i, stage := range pipeline.Stages {
+		if stage.Id == stageId {
+			index = i
+		}
+	}
+	if index == -1 {
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("Stage %s does not exist in pipeline %s", stageId, pipeline.Id))
+		return
+	}
+	stage := &pipeline.Stages[index]
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, stage)
+	case http.MethodPatch:
+		update := *stage
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input JSON")
+			return
+		}
+		update.Id = stage.Id
+		if message := validateStage(objectType, update); message != "" {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", message)
+			return
+		}
+		*stage = update
+		writeJSON(w, http.StatusOK, stage)
+	case http.MethodDelete:
+		if count := s.records[stageId]; count > 0 {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", fmt.Sprintf("Stage %s cannot be deleted because it has %d records", stageId, count))
+			return
+		}
+		pipeline.Stages = append(pipeline.Stages[:index], pipeline.Stages[index+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "VALIDATION_ERROR", "Method not allowed")
+	}
+}
+
+// validateStage returns why HubSpot would reject the stage, or "". Deal
+// stages need a probability between 0 and 1, ticket stages an OPEN or CLOSED
+// ticket state.
+func validateStage(objectType string, stage PipelineStage) string {
+	switch objectType {
+	case "deals", "0-3":
+		probability, err := strconv.ParseFloat(stage.Metadata["probability"], 64)
+		if err != nil || probability < 0 || probability > 1 {
+			return fmt.Sprintf("Stage %q needs a probability between 0 and 1", stage.Label)
+		}
+	case "tickets", "0-5":
+		if state := stage.Metadata["ticketState"]; state != "OPEN" && state != "CLOSED" {
+			return fmt.Sprintf("Stage %q needs a ticketState of OPEN or CLOSED", stage.Label)
+		}
+	}
+	return ""
+}
+
+func (s *Server) addObjectSchema(objectSchema *ObjectSchema) {
+	if objectSchema.Id == "" {
+		objectSchema.Id = s.newId()
+	}
+	if objectSchema.ObjectTypeId == "" {
+		objectSchema.ObjectTypeId = "2-" + objectSchema.Id
+	}
+	if objectSchema.FullyQualifiedName == "" {
+		objectSchema.FullyQualifiedName = "p1234_" + objectSchema.Name
+	}
+	properties := s.objectProperties(objectSchema.ObjectTypeId)
+	for _, property := range objectSchema.Properties {
+		property := property
+		if property.GroupName == "" {
+			property.GroupName = objectSchema.Name + "_information"
+		}
+		if property.Options == nil {
+			property.Options = []PropertyOption{}
+		}
+		properties[property.Name] = &property
+	}
+	objectSchema.Properties = nil
+	if objectSchema.Associations == nil {
+		objectSchema.Associations = []ObjectSchemaAssociation{}
+	}
+	s.schemas[objectSchema.ObjectTypeId] = objectSchema
+}
+
+func (s *Server) findObjectSchema(objectType string) *ObjectSchema {
+	for _, objectSchema := range s.schemas {
+		if objectSchema.ObjectTypeId == objectType || objectSchema.FullyQualifiedName == objectType {
+			return objectSchema
+		}
+	}
+	return nil
+}
+
+// objectSchemaResponse returns the custom object with its current
+// properties sorted by name.
+func (s *Server) objectSchemaResponse(objectSchema *ObjectSchema) ObjectSchema {
+	response := *objectSchema
+	response.Properties = []Property{}
+	for _, property := range s.objectProperties(objectSchema.ObjectTypeId) {
+		response.Properties = append(response.Properties, *property)
+	}
+	sort.Slice(response.Properties, func(i, j int) bool {
+		return response.Properties[i].Name < response.Properties[j].Name
+	})
+	response.Associations = append([]ObjectSchemaAssociation{}, objectSchema.Associations...)
+	return response
+}
+
+// validateObjectSchema returns why HubSpot would reject the display, required
+// and searchable properties of the schema, or "". They must name properties
+// of the object.
+func validateObjectSchema(objectSchema ObjectSchema, properties map[string]bool) string {
+	names := append([]string{objectSchema.PrimaryDisplayProperty}, objectSchema.RequiredProperties...)
+	names = append(names, objectSchema.SearchableProperties...)
+	names = append(names, objectSchema.SecondaryDisplayProperties...)
+	for _, name := range names {
+		if name != "" && !properties[name] {
+			return fmt.Sprintf("Property %s is not defined on object %s", name, objectSchema.Name)
+		}
+	}
+	return ""
+}
+
+// handleObjectSchemas serves /crm/v3/schemas, /crm/v3/schemas/{objectType}
+// and the associations of a schema below
+// /crm/v3/schemas/{objectType}/associations.
+func (s *Server) handleObjectSchemas(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			results := make([]ObjectSchema, 0, len(s.schemas))
+			for _, objectSchema := range s.schemas {
+				results = append(results, s.objectSchemaResponse(objectSchema))
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+		case http.MethodPost:
+			var objectSchema ObjectSchema
+			if err := json.NewDecoder(r.Body).Decode(&objectSchema); err != nil || objectSchema.Name == "" || objectSchema.Labels.Singular == "" || objectSchema.Labels.Plural == "" || len(objectSchema.Properties) == 0 {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Schema name, labels and properties are required")
+				return
+			}
+			defined := make(map[string]bool, len(objectSchema.Properties))
+			for _, property := range objectSchema.Properties {
+				if property.Name == "" || property.Label == "" || property.Type == "" || property.FieldType == "" {
+					writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Property name, label, type and fieldType are required")
+					return
+				}
+				defined[property.Name] = true
+			}
+			if message := validateObjectSchema(objectSchema, defined); message != "" {
+				writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", message)
+				return
+			}
+			for _, existing := range s.schemas {
+				if existing.Name == objectSchema.Name {
+					writeError(w, http.StatusConflict, "OBJECT_ALREADY_EXISTS", fmt.Sprintf("An object schema named '%s' already exists", objectSchema.Name))
+					return
+				}
+			}
+			objectSchema.Id, objectSchema.ObjectTypeId, objectSchema.FullyQualifiedName = "", "", ""
+			objectSchema.Associations = nil
+			s.addObjectSchema(&objectSchema)
+			writeJSON(w, http.StatusCreated, s.objectSchemaResponse(&objectSchema))
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "VALIDATION_ERROR", "Method not allowed")
+		}
+		return
+	}
+
+	objectSchema := s.findObjectSchema(segments[0])
+	if objectSchema == nil {
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("Object schema %s does not exist", segments[0]))
+		return
+	}
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.objectSchemaResponse(objectSchema))
+	case len(segments) == 1 && r.Method == http.MethodPatch:
+		// Only the labels and the display, required and searchable
+		// properties can be changed, fields missing from the body keep
+		// their value.
+		update := struct {
+			Labels                     *ObjectSchemaLabels `json:"labels"`
+			PrimaryDisplayProperty     *string             `json:"primaryDisplayProperty"`
+			RequiredProperties         *[]string           `json:"requiredProperties"`
+			SearchableProperties       *[]string           `json:"searchableProperties"`
+			SecondaryDisplayProperties *[]string           `json:"secondaryDisplayProperties"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid input JSON")
+			return
+		}
+		updated := *objectSchema
+		if update.Labels != nil {
+			updated.Labels = *update.Labels
+		}
+		if update.PrimaryDisplayProperty != nil {
+			updated.PrimaryDisplayProperty = *update.PrimaryDisplayProperty
+		}
+		if update.RequiredProperties != nil {
+			updated.RequiredProperties = *update.RequiredProperties
+		}
+		if update.SearchableProperties != nil {
+			updated.SearchableProperties = *update.SearchableProperties
+		}
+		if update.SecondaryDisplayProperties != nil {
+			updated.SecondaryDisplayProperties = *update.SecondaryDisplayProperties
+		}
+		defined := make(map[string]bool)
+		for name := range s.objectProperties(objectSchema.ObjectTypeId) {
+			defined[name] = true
+		}
+		if message := validateObjectSchema(updated, defined); message != "" {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", message)
+			return
+		}
+		*objectSchema = updated
+		writeJSON(w, http.StatusOK, s.objectSchemaResponse(objectSchema))
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		delete(s.schemas, objectSchema.ObjectTypeId)
+		delete(s.properties, objectSchema.ObjectTypeId)
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 2 && segments[1] == "associations" && r.Method == http.MethodPost:
+		var association ObjectSchemaAssociation
+		if err := json.NewDecoder(r.Body).Decode(&association); err != nil || association.FromObjectTypeId != objectSchema.ObjectTypeId || association.ToObjectTypeId == "" {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "fromObjectTypeId must be the object type id of the schema and toObjectTypeId is required")
+			return
+		}
+		association.Id = s.newId()
+		objectSchema.Associations = append(objectSchema.Associations, association)
+		writeJSON(w, http.StatusCreated, association)
+	case len(segments) == 3 && segments[1] == "associations" && r.Method == http.MethodDelete:
+		for i, association := range objectSchema.Associations {
+			if association.Id == segments[2] {
+				objectSchema.Associations = append(objectSchema.Associations[:i], objectSchema.Associations[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("Association %s does not exist", segments[2]))
+	default:
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", fmt.Sprintf("%s %s is not emulated", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) sortedUsers() []*User {
+	users := make([]*User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Id < users[j].Id
+	})
+	return users
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, category, message string) {
+	writeJSON(w, status, map[string]string{
+		"status":        "error",
+		"message":       message,
+		"correlationId": "00000000-0000-0000-0000-000000000000",
+		"category":      category,
+	})
+}
@@ -4,29 +4,169 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
+const TokenURL string = "https://api.hubapi.com/oauth/v1/token"
+
+// expiryDelta is how long before its expiry an access token is refreshed.
+const expiryDelta = 5 * time.Minute
+
 type GetTokenResponse struct {
 	RefreshToken string `json:"refresh_token"`
 	AccessToken  string `json:"access_token"`
-	ExpiresIn    string `json:"expires_in"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// OAuthError is the error body returned by HubSpot's OAuth token endpoint.
+type OAuthError struct {
+	StatusCode    int    `json:"-"`
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	CorrelationId string `json:"correlationId"`
 }
 
-func GenerateToken(clientId, clientSecret, refreshToken string) string {
-	url := "https://api.hubapi.com/oauth/v1/token"
+func (e *OAuthError) Error() string {
+	msg := fmt.Sprintf("%s: %s (status %s, StatusCode = %d", e.Summary(), e.Message, e.Status, e.StatusCode)
+	if e.CorrelationId != "" {
+		msg += ", correlationId " + e.CorrelationId
+	}
+	return msg + ")"
+}
+
+// Summary describes the failure in terms of the provider configuration.
+func (e *OAuthError) Summary() string {
+	switch e.Status {
+	case "BAD_REFRESH_TOKEN":
+		return "refresh token revoked or unknown"
+	case "BAD_CLIENT_ID":
+		return "unknown client_id"
+	case "BAD_CLIENT_SECRET", "INVALID_CLIENT":
+		return "client_secret does not match client_id"
+	case "BAD_GRANT_TYPE":
+		return "refresh token grant rejected"
+	}
+	return "access token request failed"
+}
+
+func GenerateToken(clientId, clientSecret, refreshToken string) (string, error) {
+	token, err := requestToken(&http.Client{}, TokenURL, clientId, clientSecret, refreshToken)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+func requestToken(client *http.Client, tokenURL, clientId, clientSecret, refreshToken string) (*GetTokenResponse, error) {
 	method := "POST"
-	payload := strings.NewReader(fmt.Sprintf("grant_type=refresh_token&client_id=%s&client_secret=%s&refresh_token=%s", clientId, clientSecret, refreshToken))
+	payload := strings.NewReader(url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {clientId},
+		"client_secret": {clientSecret},
+		"refresh_token": {refreshToken},
+	}.Encode())
 
-	client := &http.Client{}
-	req, _ := http.NewRequest(method, url, payload)
+	req, err := http.NewRequest(method, tokenURL, payload)
+	if err != nil {
+		return nil, fmt.Errorf("building access token request: %w", err)
+	}
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
 
-	res, _ := client.Do(req)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting access token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		oauthErr := &OAuthError{StatusCode: res.StatusCode}
+		if err := json.NewDecoder(res.Body).Decode(oauthErr); err != nil {
+			oauthErr.Message = http.StatusText(res.StatusCode)
+		}
+		return nil, oauthErr
+	}
 
 	token := &GetTokenResponse{}
-	_ = json.NewDecoder(res.Body).Decode(token)
+	if err := json.NewDecoder(res.Body).Decode(token); err != nil {
+		return nil, fmt.Errorf("decoding access token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("access token response did not contain an access_token")
+	}
+	return token, nil
+}
+
+// RefreshSource hands out OAuth access tokens, exchanging the refresh token
+// for a new access token shortly before the current one expires.
+type RefreshSource struct {
+	ClientId     string
+	ClientSecret string
+	RefreshToken string
+	TokenURL     string
+	HTTPClient   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+func NewRefreshSource(clientId, clientSecret, refreshToken string) *RefreshSource {
+	return &RefreshSource{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		RefreshToken: refreshToken,
+		TokenURL:     TokenURL,
+		HTTPClient:   &http.Client{},
+	}
+}
+
+// Token returns the cached access token, refreshing it first if it is
+// missing or about to expire.
+func (s *RefreshSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accessToken != "" && time.Now().Add(expiryDelta).Before(s.expiry) {
+		return s.accessToken, nil
+	}
+	return s.refresh()
+}
+
+// Refresh exchanges the refresh token for a new access token after failed
+// was rejected. If the cached token is no longer failed, another request
+// already refreshed it and the cached token is returned.
+func (s *RefreshSource) Refresh(failed string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accessToken != "" && s.accessToken != failed {
+		return s.accessToken, nil
+	}
+	return s.refresh()
+}
+
+func (s *RefreshSource) refresh() (string, error) {
+	token, err := requestToken(s.HTTPClient, s.TokenURL, s.ClientId, s.ClientSecret, s.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	s.accessToken = token.AccessToken
+	s.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	if token.RefreshToken != "" {
+		s.RefreshToken = token.RefreshToken
+	}
+	return s.accessToken, nil
+}
+
+// StaticSource is an access token that never expires.
+type StaticSource string
+
+func (s StaticSource) Token() (string, error) {
+	return string(s), nil
+}
 
-	return token.AccessToken
+func (s StaticSource) Refresh(failed string) (string, error) {
+	return string(s), nil
 }
@@ -0,0 +1,98 @@
+package token
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTokenServer(t *testing.T, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("refresh_token") != "refresh-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"BAD_REFRESH_TOKEN","message":"missing or unknown refresh token","correlationId":"3a9c5d1e"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"refresh_token":"refresh-token","access_token":"access-token-%d","expires_in":1800}`, *requests)
+	}))
+}
+
+func TestRefreshSource_Token(t *testing.T) {
+	requests := 0
+	server := newTokenServer(t, &requests)
+	defer server.Close()
+
+	source := NewRefreshSource("client-id", "client-secret", "refresh-token")
+	source.TokenURL = server.URL
+	accessToken, err := source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token-1", accessToken)
+
+	// The cached token is reused until it is about to expire.
+	accessToken, err = source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token-1", accessToken)
+	assert.Equal(t, 1, requests)
+
+	source.expiry = time.Now().Add(expiryDelta / 2)
+	accessToken, err = source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token-2", accessToken)
+
+	accessToken, err = source.Refresh("access-token-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token-3", accessToken)
+}
+
+func TestRefreshSource_ConcurrentRefresh(t *testing.T) {
+	requests := 0
+	server := newTokenServer(t, &requests)
+	defer server.Close()
+
+	source := NewRefreshSource("client-id", "client-secret", "refresh-token")
+	source.TokenURL = server.URL
+	failed, err := source.Token()
+	assert.NoError(t, err)
+
+	// Requests rejected with the same token share a single refresh.
+	var wg sync.WaitGroup
+	accessTokens := make([]string, 10)
+	for i := range accessTokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			accessTokens[i], _ = source.Refresh(failed)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 2, requests)
+	for _, accessToken := range accessTokens {
+		assert.Equal(t, "access-token-2", accessToken)
+	}
+}
+
+func TestRefreshSource_Revoked(t *testing.T) {
+	requests := 0
+	server := newTokenServer(t, &requests)
+	defer server.Close()
+
+	source := NewRefreshSource("client-id", "client-secret", "revoked")
+	source.TokenURL = server.URL
+	_, err := source.Token()
+	oauthErr, ok := err.(*OAuthError)
+	if assert.True(t, ok, "expected an *OAuthError, got %v", err) {
+		assert.Equal(t, http.StatusBadRequest, oauthErr.StatusCode)
+		assert.Equal(t, "BAD_REFRESH_TOKEN", oauthErr.Status)
+		assert.Equal(t, "3a9c5d1e", oauthErr.CorrelationId)
+		assert.Equal(t, "refresh token revoked or unknown", oauthErr.Summary())
+	}
+}